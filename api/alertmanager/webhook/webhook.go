@@ -0,0 +1,360 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook provides an http.Handler receiving notifications from the
+// webhook integration of the Alertmanager, together with typed structs for the
+// notification payload (version 4).
+//
+// A minimal receiver looks like this:
+//
+//	http.Handle("/alerts", webhook.NewHandler(
+//		webhook.ReceiverFunc(func(ctx context.Context, m *webhook.Message) error {
+//			for _, a := range m.Alerts {
+//				log.Println(a.Status, a.Labels["alertname"])
+//			}
+//			return nil
+//		}),
+//		webhook.HandlerOpts{Registerer: prometheus.DefaultRegisterer},
+//	))
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Version is the version of the webhook payload supported by this package.
+const Version = "4"
+
+// DefMaxBodyBytes is the default maximum size of a notification body.
+const DefMaxBodyBytes = 10 << 20
+
+// Status is the status of a notification or of an alert.
+type Status string
+
+// Possible values for Status.
+const (
+	StatusFiring   Status = "firing"
+	StatusResolved Status = "resolved"
+)
+
+// Message is the payload of a webhook notification. It has the status "firing"
+// if at least one of its alerts is firing.
+type Message struct {
+	Version  string `json:"version"`
+	GroupKey string `json:"groupKey"`
+	// TruncatedAlerts is the number of alerts omitted because of the
+	// max_alerts setting of the webhook configuration.
+	TruncatedAlerts   uint64         `json:"truncatedAlerts"`
+	Status            Status         `json:"status"`
+	Receiver          string         `json:"receiver"`
+	GroupLabels       model.LabelSet `json:"groupLabels"`
+	CommonLabels      model.LabelSet `json:"commonLabels"`
+	CommonAnnotations model.LabelSet `json:"commonAnnotations"`
+	ExternalURL       string         `json:"externalURL"`
+	Alerts            []Alert        `json:"alerts"`
+}
+
+// Alert is an alert in a webhook notification.
+type Alert struct {
+	Status       Status         `json:"status"`
+	Labels       model.LabelSet `json:"labels"`
+	Annotations  model.LabelSet `json:"annotations"`
+	StartsAt     time.Time      `json:"startsAt"`
+	EndsAt       time.Time      `json:"endsAt"`
+	GeneratorURL string         `json:"generatorURL"`
+	Fingerprint  string         `json:"fingerprint"`
+}
+
+// Validate checks the message for consistency. It returns an error describing
+// all problems found, or nil.
+func (m *Message) Validate() error {
+	var errs []error
+	if m.Version != Version {
+		errs = append(errs, fmt.Errorf("unsupported version %q, expected %q", m.Version, Version))
+	}
+	if m.GroupKey == "" {
+		errs = append(errs, errors.New("missing group key"))
+	}
+	if m.Receiver == "" {
+		errs = append(errs, errors.New("missing receiver"))
+	}
+	if err := m.Status.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, ls := range []struct {
+		name string
+		set  model.LabelSet
+	}{
+		{"group labels", m.GroupLabels},
+		{"common labels", m.CommonLabels},
+		{"common annotations", m.CommonAnnotations},
+	} {
+		if err := validateLabelSet(ls.set); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", ls.name, err))
+		}
+	}
+	var firing bool
+	for i, a := range m.Alerts {
+		if err := a.Validate(); err != nil {
+			// Prefix each problem of the alert for readability.
+			alertErrs := []error{err}
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				alertErrs = joined.Unwrap()
+			}
+			for _, err := range alertErrs {
+				errs = append(errs, fmt.Errorf("alert %d: %w", i, err))
+			}
+		}
+		if a.Status == StatusFiring {
+			firing = true
+		}
+	}
+	if firing && m.Status == StatusResolved {
+		errs = append(errs, errors.New("status is resolved, but alerts are firing"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the alert for consistency. It returns an error describing all
+// problems found, or nil.
+func (a *Alert) Validate() error {
+	var errs []error
+	if err := a.Status.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(a.Labels) == 0 {
+		errs = append(errs, errors.New("missing labels"))
+	}
+	if err := validateLabelSet(a.Labels); err != nil {
+		errs = append(errs, fmt.Errorf("invalid labels: %w", err))
+	}
+	if err := validateLabelSet(a.Annotations); err != nil {
+		errs = append(errs, fmt.Errorf("invalid annotations: %w", err))
+	}
+	if a.StartsAt.IsZero() {
+		errs = append(errs, errors.New("missing start time"))
+	}
+	if a.Status == StatusResolved && a.EndsAt.IsZero() {
+		errs = append(errs, errors.New("missing end time of resolved alert"))
+	}
+	if a.Fingerprint == "" {
+		errs = append(errs, errors.New("missing fingerprint"))
+	}
+	return errors.Join(errs...)
+}
+
+// V1Alert converts the alert into the representation of the Prometheus HTTP
+// API. Resolved alerts become inactive. As the webhook payload does not contain
+// the value of the alerting expression, Value is left empty.
+func (a *Alert) V1Alert() v1.Alert {
+	state := v1.AlertStateFiring
+	if a.Status == StatusResolved {
+		state = v1.AlertStateInactive
+	}
+	return v1.Alert{
+		ActiveAt:    a.StartsAt,
+		Annotations: a.Annotations,
+		Labels:      a.Labels,
+		State:       state,
+	}
+}
+
+// validateLabelSet works like model.LabelSet.Validate, but checks the label
+// names in sorted order, so that the reported problem is deterministic.
+func validateLabelSet(ls model.LabelSet) error {
+	names := make(model.LabelNames, 0, len(ls))
+	for ln := range ls {
+		names = append(names, ln)
+	}
+	sort.Sort(names)
+	for _, ln := range names {
+		if !ln.IsValid() {
+			return fmt.Errorf("invalid name %q", ln)
+		}
+		if lv := ls[ln]; !lv.IsValid() {
+			return fmt.Errorf("invalid value %q for label %q", lv, ln)
+		}
+	}
+	return nil
+}
+
+func (s Status) validate() error {
+	switch s {
+	case StatusFiring, StatusResolved:
+		return nil
+	default:
+		return fmt.Errorf("invalid status %q", s)
+	}
+}
+
+// label returns the status as label value. Unknown statuses, which are only
+// passed on if validation is disabled, are all mapped to "invalid", so that
+// clients cannot create an arbitrary number of series.
+func (s Status) label() string {
+	if s.validate() != nil {
+		return "invalid"
+	}
+	return string(s)
+}
+
+// Receiver processes webhook notifications.
+type Receiver interface {
+	// Receive is called with each valid notification. The context is the
+	// context of the HTTP request, which is canceled if the Alertmanager
+	// gives up on the request. If Receive returns an error, the handler
+	// responds with 500 Internal Server Error, which makes the Alertmanager
+	// retry the notification.
+	Receive(ctx context.Context, m *Message) error
+}
+
+// ReceiverFunc is an adapter to allow the use of ordinary functions as
+// Receivers.
+type ReceiverFunc func(ctx context.Context, m *Message) error
+
+// Receive implements Receiver.
+func (f ReceiverFunc) Receive(ctx context.Context, m *Message) error {
+	return f(ctx, m)
+}
+
+// Logger is the minimal interface HandlerOpts needs for logging. Note that
+// log.Logger from the standard library implements this interface.
+type Logger interface {
+	Println(v ...interface{})
+}
+
+// HandlerOpts specifies options for NewHandler. The zero value of HandlerOpts
+// is a reasonable default.
+type HandlerOpts struct {
+	// ErrorLog specifies an optional Logger for invalid notifications and
+	// errors returned by the Receiver. If nil, errors are not logged.
+	ErrorLog Logger
+	// If Registerer is not nil, it is used to register the metrics
+	// "alertmanager_webhook_notifications_total", partitioned by
+	// "receiver" and "status", and
+	// "alertmanager_webhook_notification_errors_total", partitioned by
+	// "cause" ("invalid" or "receiver"). A failed registration causes a
+	// panic, unless the metrics are already registered, in which case they
+	// are shared.
+	Registerer prometheus.Registerer
+	// MaxBodyBytes limits the size of a notification body. Larger
+	// notifications are responded to with 413 Request Entity Too Large. If
+	// zero, DefMaxBodyBytes is used. If negative, no limit is applied.
+	MaxBodyBytes int64
+	// DisableValidation disables the validation of notifications with
+	// Message.Validate. Notifications with an unknown status are then
+	// counted with the status "invalid".
+	DisableValidation bool
+}
+
+// NewHandler returns an http.Handler that decodes webhook notifications from
+// POST requests, validates them, and passes them to r. Invalid notifications
+// are responded to with 400 Bad Request and not passed to r.
+func NewHandler(r Receiver, opts HandlerOpts) http.Handler {
+	if opts.MaxBodyBytes == 0 {
+		opts.MaxBodyBytes = DefMaxBodyBytes
+	}
+
+	notifications := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alertmanager_webhook_notifications_total",
+			Help: "Total number of valid webhook notifications received from the Alertmanager.",
+		},
+		[]string{"receiver", "status"},
+	)
+	errCnt := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alertmanager_webhook_notification_errors_total",
+			Help: "Total number of webhook notifications that could not be processed.",
+		},
+		[]string{"cause"},
+	)
+	if opts.Registerer != nil {
+		// Initialize all possibilities that can occur below.
+		errCnt.WithLabelValues("invalid")
+		errCnt.WithLabelValues("receiver")
+		notifications = register(opts.Registerer, notifications)
+		errCnt = register(opts.Registerer, errCnt)
+	}
+	logError := func(v ...interface{}) {
+		if opts.ErrorLog != nil {
+			opts.ErrorLog.Println(v...)
+		}
+	}
+
+	return http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			rsp.Header().Set("Allow", http.MethodPost)
+			http.Error(rsp, "Method not allowed, use POST.", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body := io.Reader(req.Body)
+		if opts.MaxBodyBytes > 0 {
+			body = http.MaxBytesReader(rsp, req.Body, opts.MaxBodyBytes)
+		}
+		var m Message
+		if err := json.NewDecoder(body).Decode(&m); err != nil {
+			errCnt.WithLabelValues("invalid").Inc()
+			logError("error decoding webhook notification:", err)
+			status := http.StatusBadRequest
+			var mbe *http.MaxBytesError
+			if errors.As(err, &mbe) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(rsp, "Cannot decode notification: "+err.Error(), status)
+			return
+		}
+		if !opts.DisableValidation {
+			if err := m.Validate(); err != nil {
+				errCnt.WithLabelValues("invalid").Inc()
+				logError("invalid webhook notification:", err)
+				http.Error(rsp, "Invalid notification: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		notifications.WithLabelValues(m.Receiver, m.Status.label()).Inc()
+
+		if err := r.Receive(req.Context(), &m); err != nil {
+			errCnt.WithLabelValues("receiver").Inc()
+			logError("error processing webhook notification:", err)
+			http.Error(rsp, "Error processing notification: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rsp.WriteHeader(http.StatusOK)
+	})
+}
+
+// register registers c with reg, returning the already registered collector
+// instead if there is one.
+func register(reg prometheus.Registerer, c *prometheus.CounterVec) *prometheus.CounterVec {
+	if err := reg.Register(c); err != nil {
+		are := &prometheus.AlreadyRegisteredError{}
+		if errors.As(err, are) {
+			return are.ExistingCollector.(*prometheus.CounterVec)
+		}
+		panic(err)
+	}
+	return c
+}
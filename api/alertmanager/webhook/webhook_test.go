@@ -0,0 +1,215 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const notification = `{
+  "version": "4",
+  "groupKey": "{}:{alertname=\"HighLatency\"}",
+  "truncatedAlerts": 0,
+  "status": "firing",
+  "receiver": "team-a",
+  "groupLabels": {"alertname": "HighLatency"},
+  "commonLabels": {"alertname": "HighLatency", "severity": "page"},
+  "commonAnnotations": {},
+  "externalURL": "http://alertmanager:9093",
+  "alerts": [
+    {
+      "status": "firing",
+      "labels": {"alertname": "HighLatency", "severity": "page", "instance": "a"},
+      "annotations": {"summary": "Latency is high"},
+      "startsAt": "2026-01-02T03:04:05Z",
+      "endsAt": "0001-01-01T00:00:00Z",
+      "generatorURL": "http://prometheus:9090/graph",
+      "fingerprint": "c0ffee"
+    },
+    {
+      "status": "resolved",
+      "labels": {"alertname": "HighLatency", "severity": "page", "instance": "b"},
+      "annotations": {},
+      "startsAt": "2026-01-02T03:00:00Z",
+      "endsAt": "2026-01-02T03:05:00Z",
+      "generatorURL": "http://prometheus:9090/graph",
+      "fingerprint": "decaf"
+    }
+  ]
+}`
+
+func TestHandler(t *testing.T) {
+	var (
+		received   *Message
+		receiveErr error
+	)
+	reg := prometheus.NewPedanticRegistry()
+	h := NewHandler(ReceiverFunc(func(_ context.Context, m *Message) error {
+		received = m
+		return receiveErr
+	}), HandlerOpts{Registerer: reg})
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	if code := post(notification); code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", code)
+	}
+	if len(received.Alerts) != 2 || received.Alerts[0].Fingerprint != "c0ffee" ||
+		!received.Alerts[1].EndsAt.Equal(time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC)) {
+		t.Errorf("unexpected message %+v", received)
+	}
+	if got := received.Alerts[1].V1Alert(); got.State != v1.AlertStateInactive || got.Labels["instance"] != "b" {
+		t.Errorf("unexpected v1 alert %+v", got)
+	}
+
+	received = nil
+	if code := post(strings.Replace(notification, `"version": "4"`, `"version": "3"`, 1)); code != http.StatusBadRequest || received != nil {
+		t.Errorf("got status %d for invalid version, want 400", code)
+	}
+	if code := post("{"); code != http.StatusBadRequest {
+		t.Errorf("got status %d for invalid JSON, want 400", code)
+	}
+	receiveErr = errors.New("downstream unavailable")
+	if code := post(notification); code != http.StatusInternalServerError {
+		t.Errorf("got status %d for receiver error, want 500", code)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got status %d for GET, want 405", rec.Code)
+	}
+
+	expected := `
+# HELP alertmanager_webhook_notification_errors_total Total number of webhook notifications that could not be processed.
+# TYPE alertmanager_webhook_notification_errors_total counter
+alertmanager_webhook_notification_errors_total{cause="invalid"} 2
+alertmanager_webhook_notification_errors_total{cause="receiver"} 1
+# HELP alertmanager_webhook_notifications_total Total number of valid webhook notifications received from the Alertmanager.
+# TYPE alertmanager_webhook_notifications_total counter
+alertmanager_webhook_notifications_total{receiver="team-a",status="firing"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandlerWithoutValidation(t *testing.T) {
+	var received []Status
+	reg := prometheus.NewPedanticRegistry()
+	h := NewHandler(ReceiverFunc(func(_ context.Context, m *Message) error {
+		received = append(received, m.Status)
+		return nil
+	}), HandlerOpts{Registerer: reg, DisableValidation: true})
+
+	for _, status := range []string{"firing", "unknown-1", "unknown-2", "unknown-3"} {
+		rec := httptest.NewRecorder()
+		body := strings.Replace(notification, `"status": "firing"`, `"status": "`+status+`"`, 1)
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Errorf("status %q: got status code %d, want 200", status, rec.Code)
+		}
+	}
+	if len(received) != 4 || received[3] != "unknown-3" {
+		t.Errorf("unexpected received statuses %v", received)
+	}
+
+	if got, err := testutil.GatherAndCount(reg, "alertmanager_webhook_notifications_total"); err != nil || got != 2 {
+		t.Errorf("got %d notification series (error %v), want 2", got, err)
+	}
+	expected := `
+# HELP alertmanager_webhook_notifications_total Total number of valid webhook notifications received from the Alertmanager.
+# TYPE alertmanager_webhook_notifications_total counter
+alertmanager_webhook_notifications_total{receiver="team-a",status="firing"} 1
+alertmanager_webhook_notifications_total{receiver="team-a",status="invalid"} 3
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "alertmanager_webhook_notifications_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	m := &Message{
+		Version: Version,
+		Status:  StatusResolved,
+		Alerts: []Alert{
+			{Status: StatusFiring, Labels: model.LabelSet{"alertname": "A"}},
+		},
+	}
+	err := m.Validate()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{
+		"missing group key",
+		"missing receiver",
+		"alert 0: missing start time",
+		"alert 0: missing fingerprint",
+		"status is resolved, but alerts are firing",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestValidateDeterministic(t *testing.T) {
+	m := &Message{
+		Version:           Version,
+		GroupKey:          "key",
+		Receiver:          "receiver",
+		Status:            StatusFiring,
+		GroupLabels:       model.LabelSet{"a": "\xff"},
+		CommonLabels:      model.LabelSet{"b": "\xff", "c": "\xff", "d": "\xff"},
+		CommonAnnotations: model.LabelSet{"e": "\xff"},
+		Alerts: []Alert{{
+			Status:      StatusFiring,
+			Labels:      model.LabelSet{"x": "\xff", "y": "\xff", "z": "\xff"},
+			StartsAt:    time.Unix(0, 0),
+			Fingerprint: "fp",
+		}},
+	}
+	expected := m.Validate().Error()
+	for _, want := range []string{
+		`invalid group labels: invalid value "\xff" for label "a"`,
+		`invalid common labels: invalid value "\xff" for label "b"`,
+		`invalid common annotations: invalid value "\xff" for label "e"`,
+		`alert 0: invalid labels: invalid value "\xff" for label "x"`,
+	} {
+		if !strings.Contains(expected, want) {
+			t.Errorf("error %q does not contain %q", expected, want)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if got := m.Validate().Error(); got != expected {
+			t.Fatalf("got error %q, want %q", got, expected)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -1037,8 +1038,9 @@ func (h *httpAPI) LabelNames(ctx context.Context, matches []string, startTime, e
 	for _, m := range matches {
 		q.Add("match[]", m)
 	}
+	q = addCustomURLParams(q, opts, "match[]", "start", "end")
 
-	_, body, w, err := h.client.DoGetFallback(ctx, u, q)
+	_, body, w, err := h.client.DoGetFallback(ctx, u, q, optionalHeaders(opts))
 	if err != nil {
 		return nil, w, err
 	}
@@ -1060,6 +1062,7 @@ func (h *httpAPI) LabelValues(ctx context.Context, label string, matches []strin
 	for _, m := range matches {
 		q.Add("match[]", m)
 	}
+	q = addCustomURLParams(q, opts, "match[]", "start", "end")
 
 	u.RawQuery = q.Encode()
 
@@ -1067,6 +1070,7 @@ func (h *httpAPI) LabelValues(ctx context.Context, label string, matches []strin
 	if err != nil {
 		return nil, nil, err
 	}
+	setHeaders(req, optionalHeaders(opts))
 	_, body, w, err := h.client.Do(ctx, req)
 	if err != nil {
 		return nil, w, err
@@ -1089,6 +1093,8 @@ type apiOptions struct {
 	lookbackDelta time.Duration
 	stats         StatsValue
 	limit         uint64
+	params        url.Values
+	header        http.Header
 }
 
 type Option func(c *apiOptions)
@@ -1127,11 +1133,100 @@ func WithLimit(limit uint64) Option {
 	}
 }
 
-func addOptionalURLParams(q url.Values, opts []Option) url.Values {
+// WithParameter adds an arbitrary query parameter to the request, e.g. one
+// only understood by a Prometheus-compatible backend. Parameters added
+// multiple times are sent multiple times. A parameter set by another Option
+// cannot be overridden. Parameters of the method itself (like "query",
+// "start", or "match[]") are ignored, even if the method leaves them empty.
+func WithParameter(name, value string) Option {
+	return func(o *apiOptions) {
+		if o.params == nil {
+			o.params = url.Values{}
+		}
+		o.params.Add(name, value)
+	}
+}
+
+// WithHeader sets an arbitrary HTTP header of the request, replacing any
+// previous value set with WithHeader for the same header name. The
+// "Content-Type" header is determined by the request and cannot be set.
+func WithHeader(name, value string) Option {
+	return func(o *apiOptions) {
+		if http.CanonicalHeaderKey(name) == "Content-Type" {
+			return
+		}
+		if o.header == nil {
+			o.header = http.Header{}
+		}
+		o.header.Set(name, value)
+	}
+}
+
+// WithDedup enables or disables the deduplication of series from replicas
+// in Thanos (the "dedup" parameter).
+// https://thanos.io/tip/components/query.md/#deduplication-enabled
+func WithDedup(dedup bool) Option {
+	return WithParameter("dedup", strconv.FormatBool(dedup))
+}
+
+// WithPartialResponse allows or disallows partial responses in Thanos if
+// some of the queried stores are unavailable (the "partial_response"
+// parameter).
+// https://thanos.io/tip/components/query.md/#partial-response
+func WithPartialResponse(partialResponse bool) Option {
+	return WithParameter("partial_response", strconv.FormatBool(partialResponse))
+}
+
+// WithMaxSourceResolution selects the maximum resolution of downsampled data
+// queried from Thanos (the "max_source_resolution" parameter), e.g. 5m or 1h.
+// A resolution of zero selects raw data only.
+// https://thanos.io/tip/components/query.md/#auto-downsampling
+func WithMaxSourceResolution(resolution time.Duration) Option {
+	return WithParameter("max_source_resolution", model.Duration(resolution).String())
+}
+
+// WithReplicaLabels sets the labels Thanos uses to deduplicate series from
+// replicas for this request, overriding the ones configured in the querier
+// (the "replicaLabels[]" parameter).
+func WithReplicaLabels(labels ...string) Option {
+	return func(o *apiOptions) {
+		for _, l := range labels {
+			WithParameter("replicaLabels[]", l)(o)
+		}
+	}
+}
+
+// WithEngine selects the PromQL engine used by the Thanos querier for this
+// request (the "engine" parameter), e.g. "prometheus" or "thanos".
+func WithEngine(engine string) Option {
+	return WithParameter("engine", engine)
+}
+
+// WithTenant sets the tenant of the request for multi-tenant backends like
+// Mimir and Cortex via the "X-Scope-OrgID" header. Multiple tenants are
+// joined with "|" for federated queries.
+// https://grafana.com/docs/mimir/latest/references/http-api/#authentication
+func WithTenant(tenants ...string) Option {
+	return WithHeader("X-Scope-OrgID", strings.Join(tenants, "|"))
+}
+
+// WithCacheControl sets the "Cache-Control" header of the request. For
+// example, "no-store" makes the query-frontend of Mimir and Cortex bypass its
+// results cache.
+func WithCacheControl(value string) Option {
+	return WithHeader("Cache-Control", value)
+}
+
+func newAPIOptions(opts []Option) *apiOptions {
 	opt := &apiOptions{}
 	for _, o := range opts {
 		o(opt)
 	}
+	return opt
+}
+
+func addOptionalURLParams(q url.Values, opts []Option) url.Values {
+	opt := newAPIOptions(opts)
 
 	if opt.timeout > 0 {
 		q.Set("timeout", opt.timeout.String())
@@ -1152,6 +1247,39 @@ func addOptionalURLParams(q url.Values, opts []Option) url.Values {
 	return q
 }
 
+// addCustomURLParams adds the parameters set with WithParameter to q, except
+// for those already set in q and for the reserved ones. It has to be called
+// after the method has set its own parameters, which it has to pass as
+// reserved, including the ones it leaves empty.
+func addCustomURLParams(q url.Values, opts []Option, reserved ...string) url.Values {
+	opt := newAPIOptions(opts)
+
+	for name, values := range opt.params {
+		if _, ok := q[name]; ok || slices.Contains(reserved, name) {
+			continue
+		}
+		q[name] = append([]string(nil), values...)
+	}
+
+	return q
+}
+
+// optionalHeaders returns the headers set with WithHeader, or nil if there
+// are none.
+func optionalHeaders(opts []Option) http.Header {
+	return newAPIOptions(opts).header
+}
+
+// setHeaders sets the provided headers in req, except for "Content-Type".
+func setHeaders(req *http.Request, header http.Header) {
+	for name, values := range header {
+		if name == "Content-Type" {
+			continue
+		}
+		req.Header[name] = values
+	}
+}
+
 func (h *httpAPI) Query(ctx context.Context, query string, ts time.Time, opts ...Option) (model.Value, Warnings, error) {
 	u := h.client.URL(epQuery, nil)
 	q := addOptionalURLParams(u.Query(), opts)
@@ -1160,8 +1288,9 @@ func (h *httpAPI) Query(ctx context.Context, query string, ts time.Time, opts ..
 	if !ts.IsZero() {
 		q.Set("time", formatTime(ts))
 	}
+	q = addCustomURLParams(q, opts, "query", "time")
 
-	_, body, warnings, err := h.client.DoGetFallback(ctx, u, q)
+	_, body, warnings, err := h.client.DoGetFallback(ctx, u, q, optionalHeaders(opts))
 	if err != nil {
 		return nil, warnings, err
 	}
@@ -1178,8 +1307,9 @@ func (h *httpAPI) QueryRange(ctx context.Context, query string, r Range, opts ..
 	q.Set("start", formatTime(r.Start))
 	q.Set("end", formatTime(r.End))
 	q.Set("step", strconv.FormatFloat(r.Step.Seconds(), 'f', -1, 64))
+	q = addCustomURLParams(q, opts, "query", "start", "end", "step")
 
-	_, body, warnings, err := h.client.DoGetFallback(ctx, u, q)
+	_, body, warnings, err := h.client.DoGetFallback(ctx, u, q, optionalHeaders(opts))
 	if err != nil {
 		return nil, warnings, err
 	}
@@ -1202,8 +1332,9 @@ func (h *httpAPI) Series(ctx context.Context, matches []string, startTime, endTi
 	if !endTime.IsZero() {
 		q.Set("end", formatTime(endTime))
 	}
+	q = addCustomURLParams(q, opts, "match[]", "start", "end")
 
-	_, body, warnings, err := h.client.DoGetFallback(ctx, u, q)
+	_, body, warnings, err := h.client.DoGetFallback(ctx, u, q, optionalHeaders(opts))
 	if err != nil {
 		return nil, warnings, err
 	}
@@ -1323,12 +1454,14 @@ func (h *httpAPI) Metadata(ctx context.Context, metric, limit string) (map[strin
 func (h *httpAPI) TSDB(ctx context.Context, opts ...Option) (TSDBResult, error) {
 	u := h.client.URL(epTSDB, nil)
 	q := addOptionalURLParams(u.Query(), opts)
+	q = addCustomURLParams(q, opts)
 	u.RawQuery = q.Encode()
 
 	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
 		return TSDBResult{}, err
 	}
+	setHeaders(req, optionalHeaders(opts))
 
 	_, body, _, err := h.client.Do(ctx, req)
 	if err != nil {
@@ -1370,7 +1503,7 @@ func (h *httpAPI) QueryExemplars(ctx context.Context, query string, startTime, e
 		q.Set("end", formatTime(endTime))
 	}
 
-	_, body, _, err := h.client.DoGetFallback(ctx, u, q)
+	_, body, _, err := h.client.DoGetFallback(ctx, u, q, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1388,7 +1521,7 @@ type Warnings []string
 type apiClient interface {
 	URL(ep string, args map[string]string) *url.URL
 	Do(context.Context, *http.Request) (*http.Response, []byte, Warnings, error)
-	DoGetFallback(ctx context.Context, u *url.URL, args url.Values) (*http.Response, []byte, Warnings, error)
+	DoGetFallback(ctx context.Context, u *url.URL, args url.Values, header http.Header) (*http.Response, []byte, Warnings, error)
 }
 
 type apiClientImpl struct {
@@ -1468,13 +1601,15 @@ func (h *apiClientImpl) Do(ctx context.Context, req *http.Request) (*http.Respon
 }
 
 // DoGetFallback will attempt to do the request as-is, and on a 405 or 501 it
-// will fallback to a GET request.
-func (h *apiClientImpl) DoGetFallback(ctx context.Context, u *url.URL, args url.Values) (*http.Response, []byte, Warnings, error) {
+// will fallback to a GET request. The provided headers are added to both
+// requests, except for "Content-Type".
+func (h *apiClientImpl) DoGetFallback(ctx context.Context, u *url.URL, args url.Values, header http.Header) (*http.Response, []byte, Warnings, error) {
 	encodedArgs := args.Encode()
 	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(encodedArgs))
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	setHeaders(req, header)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	// Following comment originates from https://pkg.go.dev/net/http#Transport
 	// Transport only retries a request upon encountering a network error if the request is
@@ -1492,6 +1627,7 @@ func (h *apiClientImpl) DoGetFallback(ctx context.Context, u *url.URL, args url.
 		if err != nil {
 			return nil, nil, warnings, err
 		}
+		setHeaders(req, header)
 		return h.Do(ctx, req)
 	}
 	return resp, body, warnings, err
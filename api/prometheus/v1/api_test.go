@@ -30,6 +30,8 @@ import (
 	json "github.com/json-iterator/go"
 
 	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
 )
 
 type apiTest struct {
@@ -89,11 +91,12 @@ func (c *apiTestClient) Do(_ context.Context, req *http.Request) (*http.Response
 	return resp, b, test.inWarnings, test.inErr
 }
 
-func (c *apiTestClient) DoGetFallback(ctx context.Context, u *url.URL, args url.Values) (*http.Response, []byte, Warnings, error) {
+func (c *apiTestClient) DoGetFallback(ctx context.Context, u *url.URL, args url.Values, header http.Header) (*http.Response, []byte, Warnings, error) {
 	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(args.Encode()))
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	setHeaders(req, header)
 	return c.Do(ctx, req)
 }
 
@@ -1815,7 +1818,7 @@ func TestDoGetFallback(t *testing.T) {
 	}
 
 	// Do a post, and ensure that the post succeeds.
-	_, b, _, err := api.DoGetFallback(context.TODO(), u, v)
+	_, b, _, err := api.DoGetFallback(context.TODO(), u, v, nil)
 	if err != nil {
 		t.Fatalf("Error doing local request: %v", err)
 	}
@@ -1832,7 +1835,7 @@ func TestDoGetFallback(t *testing.T) {
 
 	// Do a fallback to a get on 405.
 	u.Path = "/blockPost405"
-	_, b, _, err = api.DoGetFallback(context.TODO(), u, v)
+	_, b, _, err = api.DoGetFallback(context.TODO(), u, v, nil)
 	if err != nil {
 		t.Fatalf("Error doing local request: %v", err)
 	}
@@ -1848,7 +1851,7 @@ func TestDoGetFallback(t *testing.T) {
 
 	// Do a fallback to a get on 501.
 	u.Path = "/blockPost501"
-	_, b, _, err = api.DoGetFallback(context.TODO(), u, v)
+	_, b, _, err = api.DoGetFallback(context.TODO(), u, v, nil)
 	if err != nil {
 		t.Fatalf("Error doing local request: %v", err)
 	}
@@ -1862,3 +1865,115 @@ func TestDoGetFallback(t *testing.T) {
 		t.Fatalf("Mismatch in values")
 	}
 }
+
+func TestBackendOptions(t *testing.T) {
+	var (
+		gotMethod string
+		gotForm   url.Values
+		gotHeader http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		gotMethod, gotForm, gotHeader = req.Method, req.Form, req.Header
+		switch req.URL.Path {
+		case "/api/v1/query", "/api/v1/query_range":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(api.Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	promAPI := NewAPI(client)
+	opts := []Option{
+		WithDedup(false),
+		WithPartialResponse(true),
+		WithMaxSourceResolution(5 * time.Minute),
+		WithReplicaLabels("replica", "prometheus_replica"),
+		WithEngine("thanos"),
+		WithParameter("custom", "a"),
+		WithParameter("custom", "b"),
+		WithParameter("query", "ignored"),
+		WithParameter("match[]", "ignored"),
+		WithParameter("start", "ignored"),
+		WithTenant("team-a", "team-b"),
+		WithCacheControl("no-store"),
+		WithHeader("X-Custom", "value"),
+		WithHeader("Content-Type", "text/plain"),
+	}
+	wantForm := url.Values{
+		"dedup":                 {"false"},
+		"partial_response":      {"true"},
+		"max_source_resolution": {"5m"},
+		"replicaLabels[]":       {"replica", "prometheus_replica"},
+		"engine":                {"thanos"},
+		"custom":                {"a", "b"},
+	}
+	wantHeader := map[string]string{
+		"X-Scope-OrgID": "team-a|team-b",
+		"Cache-Control": "no-store",
+		"X-Custom":      "value",
+	}
+
+	// The parameters of the methods themselves must not be changed, even
+	// if left empty.
+	wantReserved := map[string]url.Values{
+		"Query":       {"query": {"up"}},
+		"QueryRange":  {"query": {"up"}, "start": {"0"}},
+		"Series":      {"match[]": {"up"}, "start": nil},
+		"LabelNames":  {"match[]": nil, "start": nil},
+		"LabelValues": {"match[]": nil, "start": nil},
+	}
+
+	ctx := context.Background()
+	for name, do := range map[string]func() error{
+		"Query": func() error {
+			_, _, err := promAPI.Query(ctx, "up", time.Time{}, opts...)
+			return err
+		},
+		"QueryRange": func() error {
+			_, _, err := promAPI.QueryRange(ctx, "up", Range{Start: time.Unix(0, 0), End: time.Unix(60, 0), Step: time.Minute}, opts...)
+			return err
+		},
+		"Series": func() error {
+			_, _, err := promAPI.Series(ctx, []string{"up"}, time.Time{}, time.Time{}, opts...)
+			return err
+		},
+		"LabelNames": func() error {
+			_, _, err := promAPI.LabelNames(ctx, nil, time.Time{}, time.Time{}, opts...)
+			return err
+		},
+		"LabelValues": func() error {
+			_, _, err := promAPI.LabelValues(ctx, "job", nil, time.Time{}, time.Time{}, opts...)
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := do(); err != nil {
+				t.Fatal(err)
+			}
+			for param, want := range wantForm {
+				if got := gotForm[param]; !reflect.DeepEqual(got, want) {
+					t.Errorf("parameter %q: want %q, got %q", param, want, got)
+				}
+			}
+			for param, want := range wantReserved[name] {
+				if got := gotForm[param]; !reflect.DeepEqual(got, want) {
+					t.Errorf("reserved parameter %q: want %q, got %q", param, want, got)
+				}
+			}
+			if got := gotHeader.Get("Content-Type"); gotMethod == http.MethodPost && got != "application/x-www-form-urlencoded" {
+				t.Errorf("Content-Type of POST request was overridden: %q", got)
+			}
+			for header, want := range wantHeader {
+				if got := gotHeader.Get(header); got != want {
+					t.Errorf("header %q: want %q, got %q", header, want, got)
+				}
+			}
+		})
+	}
+}
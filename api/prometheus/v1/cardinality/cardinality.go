@@ -0,0 +1,364 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cardinality explores the series cardinality of a Prometheus server
+// through its HTTP API. It answers which labels of a metric have the most
+// distinct values, which label values contribute the most series, and how many
+// series have been added or removed between two time ranges.
+//
+// All requests are bounded with limits and issued sequentially, so that an
+// Explorer is safe to run against production servers. If a limit is hit, the
+// affected numbers are lower bounds and the report marks them as truncated.
+package cardinality
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// Opts bundles the options for creating an Explorer. All fields are optional
+// and default to conservative values.
+type Opts struct {
+	// SeriesLimit is the maximum number of series requested per Series
+	// call. The default is 10000.
+	SeriesLimit uint64
+	// LabelValuesLimit is the maximum number of values requested per
+	// LabelValues call. The default is 1000.
+	LabelValuesLimit uint64
+	// LabelNamesLimit is the maximum number of label names requested and
+	// inspected. The default is 100.
+	LabelNamesLimit uint64
+	// TopN is the number of entries in each ranking of the report. The
+	// default is 10.
+	TopN int
+	// RequestTimeout is the timeout of each individual request. The
+	// default is 30s.
+	RequestTimeout time.Duration
+	// SkipTSDBStats disables fetching the head statistics of the TSDB,
+	// e.g. for backends that do not implement the TSDB status endpoint.
+	SkipTSDBStats bool
+}
+
+// TimeRange is the time range the series are selected from.
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// LabelNameStat is the number of distinct values of a label name.
+type LabelNameStat struct {
+	Name   string `json:"name"`
+	Values int    `json:"values"`
+	// Truncated is set if the number of values hit the LabelValuesLimit.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// LabelValueStat is the number of series carrying a label value.
+type LabelValueStat struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Series int    `json:"series"`
+}
+
+// Growth compares the series of two time ranges.
+type Growth struct {
+	Previous       TimeRange `json:"previous"`
+	PreviousSeries int       `json:"previousSeries"`
+	// Added and Removed are the numbers of series only present in the
+	// current and previous time range, respectively.
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	// TopGrowingLabels are the label names with the most values that
+	// only appear in added series. The metric name is not considered a
+	// label name.
+	TopGrowingLabels []LabelNameStat `json:"topGrowingLabels,omitempty"`
+	// Truncated is set if the series of either time range hit the
+	// SeriesLimit, in which case the numbers above are approximate.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Report is the result of exploring the cardinality of a selector.
+type Report struct {
+	Selector string    `json:"selector"`
+	Range    TimeRange `json:"range"`
+
+	// HeadSeries is the total number of series in the TSDB head, and
+	// TopMetrics the metric names with the most series in the head,
+	// regardless of the selector. Both are unset if SkipTSDBStats is set.
+	HeadSeries int       `json:"headSeries,omitempty"`
+	TopMetrics []v1.Stat `json:"topMetrics,omitempty"`
+
+	// Series is the number of series matching the selector.
+	Series          int  `json:"series"`
+	SeriesTruncated bool `json:"seriesTruncated,omitempty"`
+
+	// TopLabelNames are the label names with the most distinct values.
+	// The metric name is not considered a label name.
+	// LabelNamesTruncated is set if the label names hit the
+	// LabelNamesLimit, in which case not all label names have been
+	// inspected.
+	TopLabelNames       []LabelNameStat `json:"topLabelNames"`
+	LabelNamesTruncated bool            `json:"labelNamesTruncated,omitempty"`
+	// TopLabelValues are the label values contained in the most series.
+	TopLabelValues []LabelValueStat `json:"topLabelValues"`
+
+	// Growth is only set by ExploreGrowth.
+	Growth *Growth `json:"growth,omitempty"`
+
+	Warnings v1.Warnings `json:"warnings,omitempty"`
+}
+
+// Explorer explores the cardinality of series through the Prometheus HTTP
+// API. Create instances with NewExplorer.
+type Explorer struct {
+	api  v1.API
+	opts Opts
+}
+
+// NewExplorer creates a new Explorer on top of the provided API.
+func NewExplorer(api v1.API, opts Opts) *Explorer {
+	if opts.SeriesLimit == 0 {
+		opts.SeriesLimit = 10000
+	}
+	if opts.LabelValuesLimit == 0 {
+		opts.LabelValuesLimit = 1000
+	}
+	if opts.LabelNamesLimit == 0 {
+		opts.LabelNamesLimit = 100
+	}
+	if opts.TopN <= 0 {
+		opts.TopN = 10
+	}
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = 30 * time.Second
+	}
+	return &Explorer{api: api, opts: opts}
+}
+
+// Explore reports the cardinality of the series matching the provided series
+// selector, e.g. "http_requests_total" or `{job="api"}`, within the provided
+// time range.
+func (e *Explorer) Explore(ctx context.Context, selector string, r TimeRange) (*Report, error) {
+	rep, _, _, err := e.explore(ctx, selector, r)
+	return rep, err
+}
+
+// explore works like Explore but also returns the series it has fetched and
+// whether they have been truncated.
+func (e *Explorer) explore(ctx context.Context, selector string, r TimeRange) (*Report, map[model.Fingerprint]model.LabelSet, bool, error) {
+	rep := &Report{Selector: selector, Range: r}
+	matches := []string{selector}
+
+	if !e.opts.SkipTSDBStats {
+		var (
+			stats v1.TSDBResult
+			err   error
+		)
+		e.do(ctx, func(ctx context.Context) {
+			stats, err = e.api.TSDB(ctx, v1.WithLimit(uint64(e.opts.TopN)))
+		})
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("fetching TSDB stats: %w", err)
+		}
+		rep.HeadSeries = stats.HeadStats.NumSeries
+		rep.TopMetrics = stats.SeriesCountByMetricName
+	}
+
+	var (
+		names    []string
+		warnings v1.Warnings
+		err      error
+	)
+	e.do(ctx, func(ctx context.Context) {
+		names, warnings, err = e.api.LabelNames(ctx, matches, r.Start, r.End, v1.WithLimit(e.opts.LabelNamesLimit))
+	})
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("fetching label names: %w", err)
+	}
+	rep.Warnings = append(rep.Warnings, warnings...)
+	rep.LabelNamesTruncated = uint64(len(names)) >= e.opts.LabelNamesLimit
+
+	for _, name := range names {
+		if name == model.MetricNameLabel {
+			continue
+		}
+		var values model.LabelValues
+		e.do(ctx, func(ctx context.Context) {
+			values, warnings, err = e.api.LabelValues(ctx, name, matches, r.Start, r.End, v1.WithLimit(e.opts.LabelValuesLimit))
+		})
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("fetching values of label %q: %w", name, err)
+		}
+		rep.Warnings = append(rep.Warnings, warnings...)
+		rep.TopLabelNames = append(rep.TopLabelNames, LabelNameStat{
+			Name:      name,
+			Values:    len(values),
+			Truncated: uint64(len(values)) >= e.opts.LabelValuesLimit,
+		})
+	}
+	rep.TopLabelNames = e.topLabelNames(rep.TopLabelNames)
+
+	series, truncated, err := e.series(ctx, matches, r, &rep.Warnings)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	rep.Series, rep.SeriesTruncated = len(series), truncated
+	rep.TopLabelValues = e.topLabelValues(series)
+	return rep, series, truncated, nil
+}
+
+// ExploreGrowth works like Explore for the current time range and
+// additionally compares the series matching the selector with those of the
+// previous time range.
+func (e *Explorer) ExploreGrowth(ctx context.Context, selector string, current, previous TimeRange) (*Report, error) {
+	rep, cur, curTruncated, err := e.explore(ctx, selector, current)
+	if err != nil {
+		return nil, err
+	}
+	prev, prevTruncated, err := e.series(ctx, []string{selector}, previous, &rep.Warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Growth{
+		Previous:       previous,
+		PreviousSeries: len(prev),
+		Truncated:      curTruncated || prevTruncated,
+	}
+	prevValues := map[model.LabelName]map[model.LabelValue]struct{}{}
+	for fp, ls := range prev {
+		if _, ok := cur[fp]; !ok {
+			g.Removed++
+		}
+		for ln, lv := range ls {
+			if prevValues[ln] == nil {
+				prevValues[ln] = map[model.LabelValue]struct{}{}
+			}
+			prevValues[ln][lv] = struct{}{}
+		}
+	}
+	newValues := map[model.LabelName]map[model.LabelValue]struct{}{}
+	for fp, ls := range cur {
+		if _, ok := prev[fp]; ok {
+			continue
+		}
+		g.Added++
+		for ln, lv := range ls {
+			if ln == model.MetricNameLabel {
+				continue
+			}
+			if _, ok := prevValues[ln][lv]; ok {
+				continue
+			}
+			if newValues[ln] == nil {
+				newValues[ln] = map[model.LabelValue]struct{}{}
+			}
+			newValues[ln][lv] = struct{}{}
+		}
+	}
+	for ln, values := range newValues {
+		g.TopGrowingLabels = append(g.TopGrowingLabels, LabelNameStat{Name: string(ln), Values: len(values)})
+	}
+	g.TopGrowingLabels = e.topLabelNames(g.TopGrowingLabels)
+
+	rep.Growth = g
+	return rep, nil
+}
+
+// do calls fn with a context bounded by the configured request timeout.
+func (e *Explorer) do(ctx context.Context, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(ctx, e.opts.RequestTimeout)
+	defer cancel()
+	fn(ctx)
+}
+
+// series returns the series matching the provided selectors keyed by their
+// fingerprint and whether the SeriesLimit has been hit.
+func (e *Explorer) series(ctx context.Context, matches []string, r TimeRange, warnings *v1.Warnings) (map[model.Fingerprint]model.LabelSet, bool, error) {
+	var (
+		sets []model.LabelSet
+		w    v1.Warnings
+		err  error
+	)
+	e.do(ctx, func(ctx context.Context) {
+		sets, w, err = e.api.Series(ctx, matches, r.Start, r.End, v1.WithLimit(e.opts.SeriesLimit))
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("fetching series: %w", err)
+	}
+	*warnings = append(*warnings, w...)
+
+	result := make(map[model.Fingerprint]model.LabelSet, len(sets))
+	for _, ls := range sets {
+		result[ls.Fingerprint()] = ls
+	}
+	return result, uint64(len(sets)) >= e.opts.SeriesLimit, nil
+}
+
+// topLabelNames sorts the provided stats by descending number of values and
+// truncates them to TopN entries.
+func (e *Explorer) topLabelNames(stats []LabelNameStat) []LabelNameStat {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Values != stats[j].Values {
+			return stats[i].Values > stats[j].Values
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	if len(stats) > e.opts.TopN {
+		stats = stats[:e.opts.TopN]
+	}
+	return stats
+}
+
+// topLabelValues returns the TopN label values contained in the most of the
+// provided series. The metric name is not considered a label value.
+func (e *Explorer) topLabelValues(series map[model.Fingerprint]model.LabelSet) []LabelValueStat {
+	counts := map[model.LabelName]map[model.LabelValue]int{}
+	for _, ls := range series {
+		for ln, lv := range ls {
+			if ln == model.MetricNameLabel {
+				continue
+			}
+			if counts[ln] == nil {
+				counts[ln] = map[model.LabelValue]int{}
+			}
+			counts[ln][lv]++
+		}
+	}
+
+	var stats []LabelValueStat
+	for ln, values := range counts {
+		for lv, n := range values {
+			stats = append(stats, LabelValueStat{Name: string(ln), Value: string(lv), Series: n})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		switch {
+		case stats[i].Series != stats[j].Series:
+			return stats[i].Series > stats[j].Series
+		case stats[i].Name != stats[j].Name:
+			return stats[i].Name < stats[j].Name
+		}
+		return stats[i].Value < stats[j].Value
+	})
+	if len(stats) > e.opts.TopN {
+		stats = stats[:e.opts.TopN]
+	}
+	return stats
+}
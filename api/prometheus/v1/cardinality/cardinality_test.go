@@ -0,0 +1,179 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinality
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// fakeAPI serves label names, label values and series from a fixed set of
+// series per time range start. All methods not used by the Explorer panic.
+type fakeAPI struct {
+	v1.API
+	series map[time.Time][]model.LabelSet
+	// maxLabelNames, if positive, limits the number of label names
+	// returned.
+	maxLabelNames int
+	// optCounts records the number of options passed with each request.
+	optCounts []int
+}
+
+func (f *fakeAPI) TSDB(context.Context, ...v1.Option) (v1.TSDBResult, error) {
+	return v1.TSDBResult{
+		HeadStats:               v1.TSDBHeadStats{NumSeries: 1000},
+		SeriesCountByMetricName: []v1.Stat{{Name: "http_requests_total", Value: 600}},
+	}, nil
+}
+
+func (f *fakeAPI) LabelNames(_ context.Context, _ []string, start, _ time.Time, opts ...v1.Option) ([]string, v1.Warnings, error) {
+	names := map[string]struct{}{}
+	for _, ls := range f.series[start] {
+		for ln := range ls {
+			names[string(ln)] = struct{}{}
+		}
+	}
+	var result []string
+	for n := range names {
+		result = append(result, n)
+	}
+	sort.Strings(result)
+	if f.maxLabelNames > 0 && len(result) > f.maxLabelNames {
+		result = result[:f.maxLabelNames]
+	}
+	f.optCounts = append(f.optCounts, len(opts))
+	return result, nil, nil
+}
+
+func (f *fakeAPI) LabelValues(_ context.Context, label string, _ []string, start, _ time.Time, opts ...v1.Option) (model.LabelValues, v1.Warnings, error) {
+	values := map[model.LabelValue]struct{}{}
+	for _, ls := range f.series[start] {
+		if v, ok := ls[model.LabelName(label)]; ok {
+			values[v] = struct{}{}
+		}
+	}
+	var result model.LabelValues
+	for v := range values {
+		result = append(result, v)
+	}
+	f.optCounts = append(f.optCounts, len(opts))
+	return result, nil, nil
+}
+
+func (f *fakeAPI) Series(_ context.Context, _ []string, start, _ time.Time, opts ...v1.Option) ([]model.LabelSet, v1.Warnings, error) {
+	f.optCounts = append(f.optCounts, len(opts))
+	return f.series[start], v1.Warnings{"series warning"}, nil
+}
+
+func requests(path, pod string) model.LabelSet {
+	return model.LabelSet{model.MetricNameLabel: "http_requests_total", "path": model.LabelValue(path), "pod": model.LabelValue(pod)}
+}
+
+func TestExploreGrowth(t *testing.T) {
+	var (
+		prev = TimeRange{Start: time.Unix(0, 0), End: time.Unix(3600, 0)}
+		cur  = TimeRange{Start: time.Unix(3600, 0), End: time.Unix(7200, 0)}
+		api  = &fakeAPI{series: map[time.Time][]model.LabelSet{
+			prev.Start: {
+				requests("/", "a"),
+				requests("/api", "a"),
+			},
+			cur.Start: {
+				requests("/", "a"),
+				requests("/", "b"),
+				requests("/api", "b"),
+				requests("/user/1", "b"),
+				requests("/user/2", "b"),
+			},
+		}}
+	)
+
+	rep, err := NewExplorer(api, Opts{TopN: 3, SeriesLimit: 5}).ExploreGrowth(context.Background(), "http_requests_total", cur, prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rep.HeadSeries != 1000 || len(rep.TopMetrics) != 1 {
+		t.Errorf("unexpected TSDB stats: %d, %v", rep.HeadSeries, rep.TopMetrics)
+	}
+	if rep.Series != 5 || !rep.SeriesTruncated {
+		t.Errorf("got %d series (truncated: %v), want 5 truncated", rep.Series, rep.SeriesTruncated)
+	}
+
+	expectedNames := []LabelNameStat{{Name: "path", Values: 4}, {Name: "pod", Values: 2}}
+	if !reflect.DeepEqual(rep.TopLabelNames, expectedNames) {
+		t.Errorf("got top label names %v, want %v", rep.TopLabelNames, expectedNames)
+	}
+	if rep.LabelNamesTruncated {
+		t.Error("label names unexpectedly truncated")
+	}
+	expectedValues := []LabelValueStat{
+		{Name: "pod", Value: "b", Series: 4},
+		{Name: "path", Value: "/", Series: 2},
+		{Name: "path", Value: "/api", Series: 1},
+	}
+	if !reflect.DeepEqual(rep.TopLabelValues, expectedValues) {
+		t.Errorf("got top label values %v, want %v", rep.TopLabelValues, expectedValues)
+	}
+
+	expectedGrowth := &Growth{
+		Previous:       prev,
+		PreviousSeries: 2,
+		Added:          4,
+		Removed:        1,
+		TopGrowingLabels: []LabelNameStat{
+			{Name: "path", Values: 2},
+			{Name: "pod", Values: 1},
+		},
+		Truncated: true,
+	}
+	if !reflect.DeepEqual(rep.Growth, expectedGrowth) {
+		t.Errorf("got growth %+v, want %+v", rep.Growth, expectedGrowth)
+	}
+	if len(rep.Warnings) != 2 {
+		t.Errorf("got warnings %v, want one per Series call", rep.Warnings)
+	}
+	for i, n := range api.optCounts {
+		if n != 1 {
+			t.Errorf("request %d: got %d options, want exactly the limit", i, n)
+		}
+	}
+}
+
+func TestExploreLabelNamesTruncated(t *testing.T) {
+	r := TimeRange{Start: time.Unix(0, 0), End: time.Unix(3600, 0)}
+	api := &fakeAPI{
+		series:        map[time.Time][]model.LabelSet{r.Start: {requests("/", "a"), requests("/api", "b")}},
+		maxLabelNames: 2,
+	}
+
+	rep, err := NewExplorer(api, Opts{LabelNamesLimit: 2, SkipTSDBStats: true}).Explore(context.Background(), "http_requests_total", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.LabelNamesTruncated {
+		t.Error("label names not flagged as truncated")
+	}
+	expectedNames := []LabelNameStat{{Name: "path", Values: 2}}
+	if !reflect.DeepEqual(rep.TopLabelNames, expectedNames) {
+		t.Errorf("got top label names %v, want %v", rep.TopLabelNames, expectedNames)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command querycompare runs PromQL queries against two Prometheus-compatible
+// backends and reports differences between their results.
+//
+// Queries are read from the file provided via -queries (or from stdin), one
+// PromQL expression per line. Empty lines and lines starting with '#' are
+// skipped. Queries are run as instant queries, unless -range is set, in which
+// case they are run as range queries over the given duration up to -time.
+//
+// The exit code is 0 if all results are equal, 1 if differences were found and
+// 2 if an error occurred.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/api/prometheus/v1/compare"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("querycompare", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		reference     = fs.String("reference", "", "URL of the reference backend. (required)")
+		candidate     = fs.String("candidate", "", "URL of the candidate backend. (required)")
+		queriesFile   = fs.String("queries", "-", "File with one PromQL expression per line, '-' for stdin.")
+		evalTime      = fs.String("time", "", "Evaluation time (RFC3339 or Unix timestamp) of instant queries and end of range queries. Defaults to now.")
+		queryRange    = fs.Duration("range", 0, "Run range queries over this duration instead of instant queries.")
+		step          = fs.Duration("step", time.Minute, "Resolution of range queries.")
+		absTolerance  = fs.Float64("abs-tolerance", 0, "Maximum absolute difference of values considered equal.")
+		relTolerance  = fs.Float64("rel-tolerance", 0, "Maximum relative difference of values considered equal.")
+		ignoredLabels = fs.String("ignore-labels", "", "Comma-separated list of labels to ignore when aligning series.")
+		ignoreWarns   = fs.Bool("ignore-warnings", false, "Do not compare warnings.")
+		timeout       = fs.Duration("timeout", time.Minute, "Timeout of each query.")
+		jsonOutput    = fs.Bool("json", false, "Write the report as JSON.")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *reference == "" || *candidate == "" {
+		fmt.Fprintln(stderr, "Both -reference and -candidate are required.")
+		fs.Usage()
+		return 2
+	}
+
+	ts := time.Now()
+	if *evalTime != "" {
+		var err error
+		if ts, err = parseTime(*evalTime); err != nil {
+			fmt.Fprintf(stderr, "Error parsing -time: %v\n", err)
+			return 2
+		}
+	}
+
+	in := stdin
+	if *queriesFile != "-" {
+		f, err := os.Open(*queriesFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error opening queries file: %v\n", err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+	queries, err := readQueries(in, ts, *queryRange, *step)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading queries: %v\n", err)
+		return 2
+	}
+
+	refAPI, err := newAPI(*reference)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error creating reference client: %v\n", err)
+		return 2
+	}
+	candAPI, err := newAPI(*candidate)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error creating candidate client: %v\n", err)
+		return 2
+	}
+
+	opts := compare.Opts{
+		AbsoluteTolerance: *absTolerance,
+		RelativeTolerance: *relTolerance,
+		IgnoreWarnings:    *ignoreWarns,
+		QueryOptions:      []v1.Option{v1.WithTimeout(*timeout)},
+	}
+	for _, l := range strings.Split(*ignoredLabels, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			opts.IgnoredLabels = append(opts.IgnoredLabels, model.LabelName(l))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(len(queries)+1)*2*(*timeout))
+	defer cancel()
+	results, err := compare.New(refAPI, candAPI, opts).CompareAll(ctx, queries)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error comparing queries: %v\n", err)
+		return 2
+	}
+
+	write := compare.WriteText
+	if *jsonOutput {
+		write = compare.WriteJSON
+	}
+	if err := write(stdout, results); err != nil {
+		fmt.Fprintf(stderr, "Error writing report: %v\n", err)
+		return 2
+	}
+	for _, r := range results {
+		if !r.Equal() {
+			return 1
+		}
+	}
+	return 0
+}
+
+func newAPI(address string) (v1.API, error) {
+	client, err := api.NewClient(api.Config{Address: address})
+	if err != nil {
+		return nil, err
+	}
+	return v1.NewAPI(client), nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	var t model.Time
+	if err := t.UnmarshalJSON([]byte(s)); err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC3339 nor a Unix timestamp", s)
+	}
+	return t.Time(), nil
+}
+
+func readQueries(r io.Reader, ts time.Time, queryRange, step time.Duration) ([]compare.Query, error) {
+	var queries []compare.Query
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		expr := strings.TrimSpace(scanner.Text())
+		if expr == "" || strings.HasPrefix(expr, "#") {
+			continue
+		}
+		q := compare.Query{Expr: expr, Time: ts}
+		if queryRange > 0 {
+			q.Range = &v1.Range{Start: ts.Add(-queryRange), End: ts, Step: step}
+		}
+		queries = append(queries, q)
+	}
+	return queries, scanner.Err()
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// backend is a fake Prometheus HTTP API returning a single series with the
+// configured value for every query. It records the parameters of all queries.
+type backend struct {
+	*httptest.Server
+	value string
+
+	mtx     sync.Mutex
+	queries []map[string]string
+}
+
+func newBackend(t *testing.T, value string) *backend {
+	b := &backend{value: value}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		params := map[string]string{"path": r.URL.Path}
+		for k := range r.Form {
+			params[k] = r.Form.Get(k)
+		}
+		b.mtx.Lock()
+		b.queries = append(b.queries, params)
+		b.mtx.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/query":
+			fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","job":"a"},"value":[1700000000,%q]}]}}`, b.value)
+		case "/api/v1/query_range":
+			fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"a"},"values":[[1699999940,%q],[1700000000,%q]]}]}}`, b.value, b.value)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+func TestRun(t *testing.T) {
+	const queries = `
+# Comments and empty lines are skipped.
+up
+
+sum(up)
+`
+	ref := newBackend(t, "1")
+	same := newBackend(t, "1")
+	different := newBackend(t, "2")
+
+	for _, tc := range []struct {
+		name       string
+		args       []string
+		candidate  *backend
+		rangeQuery bool
+		wantCode   int
+		wantOut    string
+	}{
+		{
+			name:      "equal instant queries",
+			candidate: same,
+			args:      []string{"-time", "1700000000"},
+			wantCode:  0,
+			wantOut: `up: OK (reference: 1 series, candidate: 1 series)
+sum(up): OK (reference: 1 series, candidate: 1 series)
+0 of 2 queries returned different results
+`,
+		},
+		{
+			name:      "different instant queries",
+			candidate: different,
+			args:      []string{"-time", "2023-11-14T22:13:20Z"},
+			wantCode:  1,
+			wantOut: `up: 1 difference(s) (reference: 1 series, candidate: 1 series)
+	value_mismatch up{job="a"} @1700000000: reference=1 candidate=2
+sum(up): 1 difference(s) (reference: 1 series, candidate: 1 series)
+	value_mismatch up{job="a"} @1700000000: reference=1 candidate=2
+2 of 2 queries returned different results
+`,
+		},
+		{
+			name:      "differences within tolerance",
+			candidate: different,
+			args:      []string{"-time", "1700000000", "-abs-tolerance", "1"},
+			wantCode:  0,
+			wantOut: `up: OK (reference: 1 series, candidate: 1 series)
+sum(up): OK (reference: 1 series, candidate: 1 series)
+0 of 2 queries returned different results
+`,
+		},
+		{
+			name:       "equal range queries",
+			candidate:  same,
+			args:       []string{"-time", "1700000000", "-range", "1m", "-step", "30s"},
+			rangeQuery: true,
+			wantCode:   0,
+			wantOut: `up: OK (reference: 1 series, candidate: 1 series)
+sum(up): OK (reference: 1 series, candidate: 1 series)
+0 of 2 queries returned different results
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ref.queries, tc.candidate.queries = nil, nil
+			args := append([]string{"-reference", ref.URL, "-candidate", tc.candidate.URL}, tc.args...)
+			var stdout, stderr bytes.Buffer
+			if code := run(args, strings.NewReader(queries), &stdout, &stderr); code != tc.wantCode {
+				t.Errorf("got exit code %d, want %d (stderr: %s)", code, tc.wantCode, stderr.String())
+			}
+			if got := stdout.String(); got != tc.wantOut {
+				t.Errorf("got output\n%s\nwant\n%s", got, tc.wantOut)
+			}
+
+			for _, b := range []*backend{ref, tc.candidate} {
+				if len(b.queries) != 2 || b.queries[0]["query"] != "up" || b.queries[1]["query"] != "sum(up)" {
+					t.Fatalf("unexpected queries %v", b.queries)
+				}
+				q := b.queries[0]
+				if tc.rangeQuery {
+					if q["path"] != "/api/v1/query_range" || q["start"] != "1699999940" || q["end"] != "1700000000" || q["step"] != "30" {
+						t.Errorf("unexpected range query %v", q)
+					}
+				} else if q["path"] != "/api/v1/query" || q["time"] != "1700000000" {
+					t.Errorf("unexpected instant query %v", q)
+				}
+			}
+		})
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	ref := newBackend(t, "1")
+	cand := newBackend(t, "3")
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-reference", ref.URL, "-candidate", cand.URL, "-time", "1700000000", "-json"}
+	if code := run(args, strings.NewReader("up\n"), &stdout, &stderr); code != 1 {
+		t.Errorf("got exit code %d, want 1 (stderr: %s)", code, stderr.String())
+	}
+	var results []struct {
+		Differences []struct {
+			Kind      string `json:"kind"`
+			Reference string `json:"reference"`
+			Candidate string `json:"candidate"`
+		} `json:"differences"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", stdout.String(), err)
+	}
+	if len(results) != 1 || len(results[0].Differences) != 1 ||
+		results[0].Differences[0].Kind != "value_mismatch" || results[0].Differences[0].Candidate != "3" {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	ref := newBackend(t, "1")
+
+	for _, tc := range []struct {
+		name       string
+		args       []string
+		wantStderr string
+	}{
+		{
+			name:       "unknown flag",
+			args:       []string{"-unknown"},
+			wantStderr: "flag provided but not defined",
+		},
+		{
+			name:       "missing candidate",
+			args:       []string{"-reference", ref.URL},
+			wantStderr: "Both -reference and -candidate are required.",
+		},
+		{
+			name:       "invalid time",
+			args:       []string{"-reference", ref.URL, "-candidate", ref.URL, "-time", "yesterday"},
+			wantStderr: "Error parsing -time",
+		},
+		{
+			name:       "missing queries file",
+			args:       []string{"-reference", ref.URL, "-candidate", ref.URL, "-queries", "does/not/exist"},
+			wantStderr: "Error opening queries file",
+		},
+		{
+			name:       "failing backend",
+			args:       []string{"-reference", ref.URL, "-candidate", ref.URL + "/not-found"},
+			wantStderr: "Error comparing queries",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tc.args, strings.NewReader("up\n"), &stdout, &stderr); code != 2 {
+				t.Errorf("got exit code %d, want 2", code)
+			}
+			if !strings.Contains(stderr.String(), tc.wantStderr) {
+				t.Errorf("stderr %q does not contain %q", stderr.String(), tc.wantStderr)
+			}
+		})
+	}
+}
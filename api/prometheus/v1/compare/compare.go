@@ -0,0 +1,460 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compare runs PromQL queries against two Prometheus-compatible
+// backends and reports how their results differ. A typical use case is the
+// migration from one backend to another, e.g. from Prometheus to a long-term
+// storage, where the original backend serves as the reference and the new one
+// as the candidate.
+//
+// Results are aligned per series, based on their label sets with the
+// configured ignored labels removed. Float values and native histograms are
+// compared with a configurable tolerance.
+package compare
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// DifferenceKind describes how a result of the candidate backend differs from
+// the result of the reference backend.
+type DifferenceKind string
+
+const (
+	// MissingSeries is a series returned by the reference but not by the
+	// candidate.
+	MissingSeries DifferenceKind = "missing_series"
+	// ExtraSeries is a series returned by the candidate but not by the
+	// reference.
+	ExtraSeries DifferenceKind = "extra_series"
+	// DuplicateSeries is a series that cannot be aligned unambiguously
+	// because several series of the same result are identical once the
+	// ignored labels are removed.
+	DuplicateSeries DifferenceKind = "duplicate_series"
+	// MissingSample is a sample present in the reference but not in the
+	// candidate series.
+	MissingSample DifferenceKind = "missing_sample"
+	// ExtraSample is a sample present in the candidate but not in the
+	// reference series.
+	ExtraSample DifferenceKind = "extra_sample"
+	// ValueMismatch is a float sample whose values differ by more than the
+	// configured tolerance.
+	ValueMismatch DifferenceKind = "value_mismatch"
+	// HistogramMismatch is a native histogram sample whose count, sum or
+	// buckets differ by more than the configured tolerance, or a sample
+	// that is a float in one result and a native histogram in the other.
+	HistogramMismatch DifferenceKind = "histogram_mismatch"
+	// TypeMismatch is a result of a different value type, e.g. a scalar
+	// in the reference and a vector in the candidate.
+	TypeMismatch DifferenceKind = "type_mismatch"
+	// WarningsMismatch is a different set of warnings returned with the
+	// results.
+	WarningsMismatch DifferenceKind = "warnings_mismatch"
+)
+
+// Query is a PromQL query to run against both backends.
+type Query struct {
+	// Name optionally identifies the query in reports. If empty, Expr is
+	// used.
+	Name string `json:"name,omitempty"`
+	// Expr is the PromQL expression to evaluate.
+	Expr string `json:"expr"`
+	// Time is the evaluation time of an instant query. If zero, the
+	// current time is used. It is ignored for range queries.
+	Time time.Time `json:"time,omitempty"`
+	// Range turns the query into a range query if non-nil.
+	Range *v1.Range `json:"range,omitempty"`
+}
+
+func (q Query) String() string {
+	if q.Name != "" {
+		return q.Name
+	}
+	return q.Expr
+}
+
+// Opts configures a Comparer.
+type Opts struct {
+	// AbsoluteTolerance is the maximum absolute difference between two
+	// values that are still considered equal.
+	AbsoluteTolerance float64
+	// RelativeTolerance is the maximum difference between two values,
+	// relative to the larger of their absolute values, that are still
+	// considered equal. Two values are considered equal if they are
+	// within either tolerance.
+	RelativeTolerance float64
+	// IgnoredLabels are removed from all series before aligning and
+	// reporting them. Typical candidates are labels that are added by only
+	// one of the backends, e.g. a replica or tenant label.
+	IgnoredLabels []model.LabelName
+	// IgnoreWarnings disables the comparison of warnings.
+	IgnoreWarnings bool
+	// QueryOptions are passed to every query against both backends.
+	QueryOptions []v1.Option
+}
+
+// Difference is a single difference between the reference and the candidate
+// result of a query.
+type Difference struct {
+	Kind DifferenceKind `json:"kind"`
+	// Series is the affected series with the ignored labels removed. It is
+	// empty for differences concerning the result as a whole.
+	Series model.Metric `json:"series,omitempty"`
+	// Timestamp is the timestamp of the affected sample, if any.
+	Timestamp model.Time `json:"timestamp,omitempty"`
+	// Reference and Candidate describe what each backend returned.
+	Reference string `json:"reference,omitempty"`
+	Candidate string `json:"candidate,omitempty"`
+}
+
+func (d Difference) String() string {
+	var sb strings.Builder
+	sb.WriteString(string(d.Kind))
+	if d.Series != nil {
+		sb.WriteString(" ")
+		sb.WriteString(d.Series.String())
+	}
+	if d.Timestamp != 0 {
+		fmt.Fprintf(&sb, " @%s", d.Timestamp)
+	}
+	if d.Reference != "" || d.Candidate != "" {
+		fmt.Fprintf(&sb, ": reference=%s candidate=%s", orNone(d.Reference), orNone(d.Candidate))
+	}
+	return sb.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// Result is the outcome of comparing the results of a single query.
+type Result struct {
+	Query Query `json:"query"`
+	// Differences are sorted by series and timestamp, with differences
+	// concerning the result as a whole first.
+	Differences []Difference `json:"differences,omitempty"`
+	// ReferenceSeries and CandidateSeries are the number of distinct
+	// series, with the ignored labels removed, returned by each backend.
+	ReferenceSeries int `json:"referenceSeries"`
+	CandidateSeries int `json:"candidateSeries"`
+}
+
+// Equal returns whether no differences have been found.
+func (r *Result) Equal() bool {
+	return len(r.Differences) == 0
+}
+
+// Comparer compares query results of a reference and a candidate backend.
+// Create instances with New.
+type Comparer struct {
+	reference, candidate v1.API
+	opts                 Opts
+	ignored              map[model.LabelName]struct{}
+
+	// now is for testing purposes, by default it's time.Now.
+	now func() time.Time
+}
+
+// New creates a new Comparer for the provided backends.
+func New(reference, candidate v1.API, opts Opts) *Comparer {
+	ignored := make(map[model.LabelName]struct{}, len(opts.IgnoredLabels))
+	for _, l := range opts.IgnoredLabels {
+		ignored[l] = struct{}{}
+	}
+	return &Comparer{
+		reference: reference,
+		candidate: candidate,
+		opts:      opts,
+		ignored:   ignored,
+		now:       time.Now,
+	}
+}
+
+// Compare runs the provided query against both backends and compares the
+// results. An instant query without a set Time is evaluated at the same time
+// on both backends. An error is returned if either backend fails to run the
+// query.
+func (c *Comparer) Compare(ctx context.Context, q Query) (*Result, error) {
+	if q.Range == nil && q.Time.IsZero() {
+		q.Time = c.now()
+	}
+
+	refVal, refWarnings, err := c.run(ctx, c.reference, q)
+	if err != nil {
+		return nil, fmt.Errorf("querying reference backend: %w", err)
+	}
+	candVal, candWarnings, err := c.run(ctx, c.candidate, q)
+	if err != nil {
+		return nil, fmt.Errorf("querying candidate backend: %w", err)
+	}
+
+	res := &Result{Query: q}
+	if !c.opts.IgnoreWarnings {
+		res.Differences = append(res.Differences, compareWarnings(refWarnings, candWarnings)...)
+	}
+	if refVal != nil && candVal != nil && refVal.Type() != candVal.Type() {
+		res.Differences = append(res.Differences, Difference{
+			Kind:      TypeMismatch,
+			Reference: refVal.Type().String(),
+			Candidate: candVal.Type().String(),
+		})
+		return res, nil
+	}
+
+	refSeries, refDuplicates := c.seriesOf(refVal)
+	candSeries, candDuplicates := c.seriesOf(candVal)
+	res.ReferenceSeries, res.CandidateSeries = len(refSeries), len(candSeries)
+
+	// Series that collide in either result cannot be aligned, so they are
+	// reported once and excluded from the comparison on both sides.
+	for fp, m := range candDuplicates {
+		refDuplicates[fp] = m
+	}
+	for fp, m := range refDuplicates {
+		res.Differences = append(res.Differences, Difference{Kind: DuplicateSeries, Series: m})
+		delete(refSeries, fp)
+		delete(candSeries, fp)
+	}
+
+	for fp, ref := range refSeries {
+		cand, ok := candSeries[fp]
+		if !ok {
+			res.Differences = append(res.Differences, Difference{Kind: MissingSeries, Series: ref.metric})
+			continue
+		}
+		res.Differences = append(res.Differences, c.compareSeries(ref, cand)...)
+	}
+	for fp, cand := range candSeries {
+		if _, ok := refSeries[fp]; !ok {
+			res.Differences = append(res.Differences, Difference{Kind: ExtraSeries, Series: cand.metric})
+		}
+	}
+
+	sort.SliceStable(res.Differences, func(i, j int) bool {
+		di, dj := res.Differences[i], res.Differences[j]
+		if !di.Series.Equal(dj.Series) {
+			return di.Series.Before(dj.Series)
+		}
+		return di.Timestamp < dj.Timestamp
+	})
+	return res, nil
+}
+
+// CompareAll runs Compare for all provided queries. It stops at the first
+// error, returning the results gathered so far along with the error.
+func (c *Comparer) CompareAll(ctx context.Context, qs []Query) ([]*Result, error) {
+	results := make([]*Result, 0, len(qs))
+	for _, q := range qs {
+		res, err := c.Compare(ctx, q)
+		if err != nil {
+			return results, fmt.Errorf("query %q: %w", q, err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func (c *Comparer) run(ctx context.Context, api v1.API, q Query) (model.Value, v1.Warnings, error) {
+	if q.Range != nil {
+		return api.QueryRange(ctx, q.Expr, *q.Range, c.opts.QueryOptions...)
+	}
+	return api.Query(ctx, q.Expr, q.Time, c.opts.QueryOptions...)
+}
+
+// series is a single series of a query result with its samples keyed by
+// timestamp.
+type series struct {
+	metric     model.Metric
+	floats     map[model.Time]model.SampleValue
+	histograms map[model.Time]*model.SampleHistogram
+}
+
+// seriesOf converts the provided query result into series keyed by the
+// fingerprint of their label sets without the ignored labels. Label sets that
+// collide once the ignored labels are removed are returned separately.
+func (c *Comparer) seriesOf(v model.Value) (result map[model.Fingerprint]*series, duplicates map[model.Fingerprint]model.Metric) {
+	result = map[model.Fingerprint]*series{}
+	duplicates = map[model.Fingerprint]model.Metric{}
+	add := func(m model.Metric) *series {
+		m = c.stripIgnored(m)
+		fp := m.Fingerprint()
+		if _, ok := result[fp]; ok {
+			duplicates[fp] = m
+		}
+		s := &series{
+			metric:     m,
+			floats:     map[model.Time]model.SampleValue{},
+			histograms: map[model.Time]*model.SampleHistogram{},
+		}
+		result[fp] = s
+		return s
+	}
+
+	switch v := v.(type) {
+	case *model.Scalar:
+		add(model.Metric{}).floats[v.Timestamp] = v.Value
+	case model.Vector:
+		for _, smpl := range v {
+			s := add(smpl.Metric)
+			if smpl.Histogram != nil {
+				s.histograms[smpl.Timestamp] = smpl.Histogram
+			} else {
+				s.floats[smpl.Timestamp] = smpl.Value
+			}
+		}
+	case model.Matrix:
+		for _, ss := range v {
+			s := add(ss.Metric)
+			for _, p := range ss.Values {
+				s.floats[p.Timestamp] = p.Value
+			}
+			for _, p := range ss.Histograms {
+				s.histograms[p.Timestamp] = p.Histogram
+			}
+		}
+	}
+	return result, duplicates
+}
+
+func (c *Comparer) stripIgnored(m model.Metric) model.Metric {
+	if len(c.ignored) == 0 {
+		return m
+	}
+	stripped := make(model.Metric, len(m))
+	for ln, lv := range m {
+		if _, ok := c.ignored[ln]; !ok {
+			stripped[ln] = lv
+		}
+	}
+	return stripped
+}
+
+func (c *Comparer) compareSeries(ref, cand *series) []Difference {
+	var diffs []Difference
+	diff := func(kind DifferenceKind, ts model.Time, refDesc, candDesc string) {
+		diffs = append(diffs, Difference{
+			Kind:      kind,
+			Series:    ref.metric,
+			Timestamp: ts,
+			Reference: refDesc,
+			Candidate: candDesc,
+		})
+	}
+
+	for ts, rv := range ref.floats {
+		if cv, ok := cand.floats[ts]; ok {
+			if !c.equalFloat(float64(rv), float64(cv)) {
+				diff(ValueMismatch, ts, rv.String(), cv.String())
+			}
+			continue
+		}
+		if ch, ok := cand.histograms[ts]; ok {
+			diff(HistogramMismatch, ts, rv.String(), ch.String())
+			continue
+		}
+		diff(MissingSample, ts, rv.String(), "")
+	}
+	for ts, rh := range ref.histograms {
+		if ch, ok := cand.histograms[ts]; ok {
+			if !c.equalHistogram(rh, ch) {
+				diff(HistogramMismatch, ts, rh.String(), ch.String())
+			}
+			continue
+		}
+		if cv, ok := cand.floats[ts]; ok {
+			diff(HistogramMismatch, ts, rh.String(), cv.String())
+			continue
+		}
+		diff(MissingSample, ts, rh.String(), "")
+	}
+	for ts, cv := range cand.floats {
+		if _, ok := ref.floats[ts]; ok {
+			continue
+		}
+		if _, ok := ref.histograms[ts]; ok {
+			continue
+		}
+		diff(ExtraSample, ts, "", cv.String())
+	}
+	for ts, ch := range cand.histograms {
+		if _, ok := ref.histograms[ts]; ok {
+			continue
+		}
+		if _, ok := ref.floats[ts]; ok {
+			continue
+		}
+		diff(ExtraSample, ts, "", ch.String())
+	}
+	return diffs
+}
+
+// equalFloat returns whether the provided values are equal within the
+// configured tolerance. NaN is considered equal to NaN.
+func (c *Comparer) equalFloat(a, b float64) bool {
+	if a == b || (math.IsNaN(a) && math.IsNaN(b)) {
+		return true
+	}
+	d := math.Abs(a - b)
+	if d <= c.opts.AbsoluteTolerance {
+		return true
+	}
+	return d <= c.opts.RelativeTolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// equalHistogram returns whether the provided native histograms are equal
+// within the configured tolerance. Buckets have to have identical boundaries.
+func (c *Comparer) equalHistogram(a, b *model.SampleHistogram) bool {
+	if !c.equalFloat(float64(a.Count), float64(b.Count)) || !c.equalFloat(float64(a.Sum), float64(b.Sum)) {
+		return false
+	}
+	if len(a.Buckets) != len(b.Buckets) {
+		return false
+	}
+	for i, ab := range a.Buckets {
+		bb := b.Buckets[i]
+		if ab.Boundaries != bb.Boundaries || ab.Lower != bb.Lower || ab.Upper != bb.Upper {
+			return false
+		}
+		if !c.equalFloat(float64(ab.Count), float64(bb.Count)) {
+			return false
+		}
+	}
+	return true
+}
+
+func compareWarnings(ref, cand v1.Warnings) []Difference {
+	refSorted := append([]string(nil), ref...)
+	candSorted := append([]string(nil), cand...)
+	sort.Strings(refSorted)
+	sort.Strings(candSorted)
+	if strings.Join(refSorted, "\n") == strings.Join(candSorted, "\n") {
+		return nil
+	}
+	return []Difference{{
+		Kind:      WarningsMismatch,
+		Reference: strings.Join(refSorted, "; "),
+		Candidate: strings.Join(candSorted, "; "),
+	}}
+}
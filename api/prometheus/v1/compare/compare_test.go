@@ -0,0 +1,213 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compare
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// fakeAPI returns canned results for Query and QueryRange. All other methods
+// panic.
+type fakeAPI struct {
+	v1.API
+	value    model.Value
+	warnings v1.Warnings
+	err      error
+
+	queriedAt time.Time
+	ranges    []v1.Range
+}
+
+func (f *fakeAPI) Query(_ context.Context, _ string, ts time.Time, _ ...v1.Option) (model.Value, v1.Warnings, error) {
+	f.queriedAt = ts
+	return f.value, f.warnings, f.err
+}
+
+func (f *fakeAPI) QueryRange(_ context.Context, _ string, r v1.Range, _ ...v1.Option) (model.Value, v1.Warnings, error) {
+	f.ranges = append(f.ranges, r)
+	return f.value, f.warnings, f.err
+}
+
+func TestCompareVector(t *testing.T) {
+	hist := func(count float64) *model.SampleHistogram {
+		return &model.SampleHistogram{
+			Count: model.FloatString(count),
+			Sum:   10,
+			Buckets: model.HistogramBuckets{
+				{Boundaries: 0, Lower: 0, Upper: 1, Count: model.FloatString(count)},
+			},
+		}
+	}
+	ref := &fakeAPI{
+		value: model.Vector{
+			{Metric: model.Metric{"job": "a", "replica": "1"}, Value: 1, Timestamp: 1000},
+			{Metric: model.Metric{"job": "b"}, Value: 100, Timestamp: 1000},
+			{Metric: model.Metric{"job": "c"}, Value: model.SampleValue(math.NaN()), Timestamp: 1000},
+			{Metric: model.Metric{"job": "missing"}, Value: 1, Timestamp: 1000},
+			{Metric: model.Metric{"job": "hist"}, Histogram: hist(5), Timestamp: 1000},
+		},
+		warnings: v1.Warnings{"partial data"},
+	}
+	cand := &fakeAPI{
+		value: model.Vector{
+			{Metric: model.Metric{"job": "a", "replica": "2"}, Value: 1.05, Timestamp: 1000},
+			{Metric: model.Metric{"job": "b"}, Value: 200, Timestamp: 1000},
+			{Metric: model.Metric{"job": "c"}, Value: model.SampleValue(math.NaN()), Timestamp: 1000},
+			{Metric: model.Metric{"job": "extra"}, Value: 1, Timestamp: 1000},
+			{Metric: model.Metric{"job": "hist"}, Histogram: hist(6), Timestamp: 1000},
+		},
+	}
+
+	c := New(ref, cand, Opts{
+		AbsoluteTolerance: 0.1,
+		IgnoredLabels:     []model.LabelName{"replica"},
+	})
+	now := time.Unix(1, 0)
+	c.now = func() time.Time { return now }
+
+	res, err := c.Compare(context.Background(), Query{Expr: "up"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ref.queriedAt.Equal(now) || !cand.queriedAt.Equal(now) {
+		t.Errorf("backends queried at %v and %v, want %v", ref.queriedAt, cand.queriedAt, now)
+	}
+
+	expected := []DifferenceKind{WarningsMismatch, ValueMismatch, ExtraSeries, HistogramMismatch, MissingSeries}
+	if len(res.Differences) != len(expected) {
+		t.Fatalf("got differences %v, want kinds %v", res.Differences, expected)
+	}
+	for i, d := range res.Differences {
+		if d.Kind != expected[i] {
+			t.Errorf("difference %d: got kind %q, want %q (%v)", i, d.Kind, expected[i], d)
+		}
+	}
+	if got, want := res.Differences[1].Series, (model.Metric{"job": "b"}); !got.Equal(want) {
+		t.Errorf("got series %v, want %v", got, want)
+	}
+	if res.ReferenceSeries != 5 || res.CandidateSeries != 5 {
+		t.Errorf("got %d and %d series, want 5 each", res.ReferenceSeries, res.CandidateSeries)
+	}
+}
+
+func TestCompareMatrix(t *testing.T) {
+	ref := &fakeAPI{
+		value: model.Matrix{
+			{
+				Metric: model.Metric{"job": "a"},
+				Values: []model.SamplePair{{Timestamp: 0, Value: 100}, {Timestamp: 60000, Value: 200}},
+			},
+			{Metric: model.Metric{"job": "a", "replica": "1"}},
+			{Metric: model.Metric{"job": "a", "replica": "2"}},
+		},
+	}
+	cand := &fakeAPI{
+		value: model.Matrix{
+			{
+				Metric: model.Metric{"job": "a"},
+				Values: []model.SamplePair{{Timestamp: 60000, Value: 201}, {Timestamp: 120000, Value: 300}},
+			},
+		},
+	}
+	r := v1.Range{Start: time.Unix(0, 0), End: time.Unix(120, 0), Step: time.Minute}
+
+	c := New(ref, cand, Opts{RelativeTolerance: 0.01, IgnoredLabels: []model.LabelName{"replica"}})
+	res, err := c.Compare(context.Background(), Query{Expr: "up", Range: &r})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ref.ranges) != 1 || ref.ranges[0] != r || len(cand.ranges) != 1 {
+		t.Errorf("unexpected range queries: %v, %v", ref.ranges, cand.ranges)
+	}
+
+	// The series with replica labels collide with {job="a"} once the
+	// replica label is ignored.
+	expected := []Difference{
+		{Kind: DuplicateSeries, Series: model.Metric{"job": "a"}},
+	}
+	if len(res.Differences) != len(expected) || res.Differences[0].Kind != DuplicateSeries {
+		t.Fatalf("got differences %v, want %v", res.Differences, expected)
+	}
+
+	c = New(ref, cand, Opts{RelativeTolerance: 0.01})
+	res, err = c.Compare(context.Background(), Query{Expr: "up", Range: &r})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, d := range res.Differences {
+		kinds = append(kinds, string(d.Kind))
+	}
+	want := "missing_sample,extra_sample,missing_series,missing_series"
+	if got := strings.Join(kinds, ","); got != want {
+		t.Errorf("got difference kinds %s, want %s", got, want)
+	}
+}
+
+func TestCompareTypeMismatchAndErrors(t *testing.T) {
+	ref := &fakeAPI{value: &model.Scalar{Value: 1, Timestamp: 1000}}
+	cand := &fakeAPI{value: model.Vector{}}
+
+	res, err := New(ref, cand, Opts{}).Compare(context.Background(), Query{Expr: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Differences) != 1 || res.Differences[0].Kind != TypeMismatch {
+		t.Errorf("got differences %v, want a type mismatch", res.Differences)
+	}
+
+	cand.err = errors.New("boom")
+	results, err := New(ref, cand, Opts{}).CompareAll(context.Background(), []Query{{Expr: "1"}})
+	if err == nil || !strings.Contains(err.Error(), "candidate") {
+		t.Errorf("got error %v, want candidate error", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("got %d results, want none", len(results))
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	results := []*Result{
+		{Query: Query{Expr: "up"}, ReferenceSeries: 1, CandidateSeries: 1},
+		{
+			Query:           Query{Name: "errors", Expr: "errors_total"},
+			ReferenceSeries: 1,
+			Differences: []Difference{
+				{Kind: MissingSeries, Series: model.Metric{"job": "a"}},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteText(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	expected := `up: OK (reference: 1 series, candidate: 1 series)
+errors: 1 difference(s) (reference: 1 series, candidate: 0 series)
+	missing_series {job="a"}
+1 of 2 queries returned different results
+`
+	if got := buf.String(); got != expected {
+		t.Errorf("got\n%s\nwant\n%s", got, expected)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteText writes a human-readable report of the provided results to w. For
+// each query, it lists the number of series returned by each backend and all
+// differences found.
+func WriteText(w io.Writer, results []*Result) error {
+	var mismatched int
+	for _, r := range results {
+		status := "OK"
+		if !r.Equal() {
+			status = fmt.Sprintf("%d difference(s)", len(r.Differences))
+			mismatched++
+		}
+		if _, err := fmt.Fprintf(
+			w, "%s: %s (reference: %d series, candidate: %d series)\n",
+			r.Query, status, r.ReferenceSeries, r.CandidateSeries,
+		); err != nil {
+			return err
+		}
+		for _, d := range r.Differences {
+			if _, err := fmt.Fprintf(w, "\t%s\n", d); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d of %d queries returned different results\n", mismatched, len(results))
+	return err
+}
+
+// WriteJSON writes the provided results as a JSON array to w.
+func WriteJSON(w io.Writer, results []*Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
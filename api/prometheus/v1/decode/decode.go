@@ -0,0 +1,447 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package decode decodes the results of Query and QueryRange calls of the
+// Prometheus HTTP API into user-defined structs.
+//
+// Struct fields are mapped with the "prom" tag:
+//
+//	type Request struct {
+//		Job    string                 `prom:"job"`           // Value of the "job" label.
+//		Code   int                    `prom:"code"`          // Parsed from the label value.
+//		Path   string                 `prom:"path,optional"` // Not required in strict mode.
+//		Rate   float64                `prom:",value"`        // Float sample value.
+//		Hist   *model.SampleHistogram `prom:",histogram"`    // Native histogram sample.
+//		At     time.Time              `prom:",timestamp"`    // Sample timestamp.
+//		Labels model.Metric           `prom:",labels"`       // All labels.
+//	}
+//
+// Fields without a "prom" tag, or tagged with "-", are left alone.
+//
+// By default, labels missing from a series leave the corresponding fields at
+// their zero value. With WithStrict, missing labels that are not marked
+// optional, value fields of histogram samples (and vice versa), and value
+// fields of integer type receiving non-integral values are errors.
+//
+// With Go 1.23 or later, the results of range queries can also be consumed
+// with iterators, see Samples and Series.
+package decode
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Option configures decoding.
+type Option func(o *options)
+
+type options struct {
+	strict bool
+}
+
+// WithStrict enables strict decoding, see the package documentation.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Decode decodes the provided query result into a slice of T, which must be
+// a struct type. A vector results in one element per sample, a matrix in one
+// element per float and histogram sample of each series, and a scalar in a
+// single element without labels.
+func Decode[T any](v model.Value, opts ...Option) ([]T, error) {
+	d, err := newDecoder[T](opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []T
+	switch v := v.(type) {
+	case model.Vector:
+		result = make([]T, 0, len(v))
+		for _, s := range v {
+			t, err := d.decode(s.Metric, s.Timestamp, s.Value, s.Histogram)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, t)
+		}
+	case model.Matrix:
+		for _, ss := range v {
+			err := d.decodeStream(ss, func(t T) bool {
+				result = append(result, t)
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	case *model.Scalar:
+		t, err := d.decode(model.Metric{}, v.Timestamp, v.Value, nil)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+	return result, nil
+}
+
+// DecodeSample decodes a single sample into T, which must be a struct type.
+func DecodeSample[T any](s *model.Sample, opts ...Option) (T, error) {
+	d, err := newDecoder[T](opts)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return d.decode(s.Metric, s.Timestamp, s.Value, s.Histogram)
+}
+
+// decoder decodes samples into T.
+type decoder[T any] struct {
+	plan *plan
+	opts options
+}
+
+func newDecoder[T any](opts []Option) (*decoder[T], error) {
+	p, err := planFor(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &decoder[T]{plan: p, opts: newOptions(opts)}, nil
+}
+
+// decode decodes a single sample. h is nil for float samples.
+func (d *decoder[T]) decode(m model.Metric, ts model.Time, v model.SampleValue, h *model.SampleHistogram) (T, error) {
+	var t T
+	rv := reflect.ValueOf(&t).Elem()
+	if err := d.plan.decodeLabels(rv, m, d.opts.strict); err != nil {
+		return t, err
+	}
+	return t, d.plan.decodeSample(rv, m, ts, v, h, d.opts.strict)
+}
+
+// decodeStream decodes all samples of a series, float samples first, and
+// passes them to yield until it returns false.
+func (d *decoder[T]) decodeStream(ss *model.SampleStream, yield func(T) bool) error {
+	var base T
+	rv := reflect.ValueOf(&base).Elem()
+	if err := d.plan.decodeLabels(rv, ss.Metric, d.opts.strict); err != nil {
+		return err
+	}
+	for _, p := range ss.Values {
+		t := base
+		if err := d.plan.decodeSample(reflect.ValueOf(&t).Elem(), ss.Metric, p.Timestamp, p.Value, nil, d.opts.strict); err != nil {
+			return err
+		}
+		if !yield(t) {
+			return nil
+		}
+	}
+	for _, p := range ss.Histograms {
+		t := base
+		if err := d.plan.decodeSample(reflect.ValueOf(&t).Elem(), ss.Metric, p.Timestamp, 0, p.Histogram, d.opts.strict); err != nil {
+			return err
+		}
+		if !yield(t) {
+			return nil
+		}
+	}
+	return nil
+}
+
+type fieldKind int
+
+const (
+	fieldLabel fieldKind = iota
+	fieldValue
+	fieldHistogram
+	fieldTimestamp
+	fieldLabels
+)
+
+type field struct {
+	index    int
+	name     string
+	kind     fieldKind
+	label    model.LabelName
+	optional bool
+}
+
+// plan describes how to decode into a struct type.
+type plan struct {
+	typ    reflect.Type
+	fields []field
+}
+
+var plans sync.Map // map[reflect.Type]*plan
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	modelTimeType = reflect.TypeOf(model.Time(0))
+	histogramType = reflect.TypeOf(model.SampleHistogram{})
+	metricType    = reflect.TypeOf(model.Metric{})
+	labelSetType  = reflect.TypeOf(model.LabelSet{})
+	stringMapType = reflect.TypeOf(map[string]string{})
+	unmarshalerT  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func planFor(typ reflect.Type) (*plan, error) {
+	if p, ok := plans.Load(typ); ok {
+		return p.(*plan), nil
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot decode into %s, only struct types are supported", typ)
+	}
+
+	p := &plan{typ: typ}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag, ok := sf.Tag.Lookup("prom")
+		if !ok || tag == "-" {
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("field %s.%s is tagged but not exported", typ, sf.Name)
+		}
+		f, err := parseTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", typ, sf.Name, err)
+		}
+		f.index, f.name = i, sf.Name
+		if err := checkFieldType(f, sf.Type); err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", typ, sf.Name, err)
+		}
+		p.fields = append(p.fields, f)
+	}
+
+	actual, _ := plans.LoadOrStore(typ, p)
+	return actual.(*plan), nil
+}
+
+func parseTag(tag string) (field, error) {
+	parts := strings.Split(tag, ",")
+	f := field{label: model.LabelName(parts[0])}
+	special := 0
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "value":
+			f.kind = fieldValue
+			special++
+		case "histogram":
+			f.kind = fieldHistogram
+			special++
+		case "timestamp":
+			f.kind = fieldTimestamp
+			special++
+		case "labels":
+			f.kind = fieldLabels
+			special++
+		case "optional":
+			f.optional = true
+		default:
+			return f, fmt.Errorf("unknown tag option %q", opt)
+		}
+	}
+	switch {
+	case special > 1:
+		return f, errors.New("at most one of value, histogram, timestamp and labels may be set")
+	case special == 1 && f.label != "":
+		return f, errors.New("a label name cannot be combined with value, histogram, timestamp or labels")
+	case special == 0 && f.label == "":
+		return f, errors.New("missing label name")
+	}
+	return f, nil
+}
+
+func checkFieldType(f field, typ reflect.Type) error {
+	switch f.kind {
+	case fieldLabel:
+		if reflect.PointerTo(typ).Implements(unmarshalerT) {
+			return nil
+		}
+		switch typ.Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return nil
+		}
+	case fieldValue:
+		switch typ.Kind() {
+		case reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return nil
+		}
+	case fieldHistogram:
+		if typ == histogramType || typ == reflect.PointerTo(histogramType) {
+			return nil
+		}
+	case fieldTimestamp:
+		if typ == timeType || typ == modelTimeType || typ.Kind() == reflect.Int64 {
+			return nil
+		}
+	case fieldLabels:
+		if typ == metricType || typ == labelSetType || typ == stringMapType {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported type %s", typ)
+}
+
+func (p *plan) decodeLabels(rv reflect.Value, m model.Metric, strict bool) error {
+	for _, f := range p.fields {
+		fv := rv.Field(f.index)
+		switch f.kind {
+		case fieldLabel:
+			lv, ok := m[f.label]
+			if !ok {
+				if strict && !f.optional {
+					return fmt.Errorf("series %s has no label %q for field %s", m, f.label, f.name)
+				}
+				continue
+			}
+			if err := setLabel(fv, string(lv)); err != nil {
+				return fmt.Errorf("label %q of series %s for field %s: %w", f.label, m, f.name, err)
+			}
+		case fieldLabels:
+			switch fv.Type() {
+			case metricType:
+				fv.Set(reflect.ValueOf(m.Clone()))
+			case labelSetType:
+				fv.Set(reflect.ValueOf(model.LabelSet(m.Clone())))
+			default:
+				sm := make(map[string]string, len(m))
+				for ln, lv := range m {
+					sm[string(ln)] = string(lv)
+				}
+				fv.Set(reflect.ValueOf(sm))
+			}
+		}
+	}
+	return nil
+}
+
+func (p *plan) decodeSample(rv reflect.Value, m model.Metric, ts model.Time, v model.SampleValue, h *model.SampleHistogram, strict bool) error {
+	for _, f := range p.fields {
+		fv := rv.Field(f.index)
+		switch f.kind {
+		case fieldValue:
+			if h != nil {
+				if strict && !f.optional {
+					return fmt.Errorf("series %s has a histogram sample at %s for value field %s", m, ts, f.name)
+				}
+				continue
+			}
+			if err := setValue(fv, float64(v), strict); err != nil {
+				return fmt.Errorf("value of series %s at %s for field %s: %w", m, ts, f.name, err)
+			}
+		case fieldHistogram:
+			if h == nil {
+				if strict && !f.optional {
+					return fmt.Errorf("series %s has a float sample at %s for histogram field %s", m, ts, f.name)
+				}
+				continue
+			}
+			if fv.Kind() == reflect.Pointer {
+				fv.Set(reflect.ValueOf(h))
+			} else {
+				fv.Set(reflect.ValueOf(*h))
+			}
+		case fieldTimestamp:
+			switch fv.Type() {
+			case timeType:
+				fv.Set(reflect.ValueOf(ts.Time()))
+			default:
+				fv.SetInt(int64(ts))
+			}
+		}
+	}
+	return nil
+}
+
+func setLabel(fv reflect.Value, s string) error {
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	}
+	return nil
+}
+
+func setValue(fv reflect.Value, v float64, strict bool) error {
+	switch fv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		fv.SetFloat(v)
+		return nil
+	}
+	if strict && (v != math.Trunc(v) || math.IsInf(v, 0) || math.IsNaN(v)) {
+		return fmt.Errorf("%v is not an integer", v)
+	}
+	switch fv.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if strict && v < 0 {
+			return fmt.Errorf("%v is negative", v)
+		}
+		fv.SetUint(uint64(v))
+	default:
+		fv.SetInt(int64(v))
+	}
+	return nil
+}
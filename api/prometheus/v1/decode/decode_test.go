@@ -0,0 +1,120 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+type request struct {
+	Job    string                 `prom:"job"`
+	Code   int                    `prom:"code"`
+	Path   string                 `prom:"path,optional"`
+	Rate   float64                `prom:",value"`
+	Hist   *model.SampleHistogram `prom:",histogram,optional"`
+	At     time.Time              `prom:",timestamp"`
+	Labels model.Metric           `prom:",labels"`
+	Other  string
+}
+
+func TestDecodeVector(t *testing.T) {
+	hist := &model.SampleHistogram{Count: 2, Sum: 3}
+	v := model.Vector{
+		{Metric: model.Metric{"job": "api", "code": "200", "path": "/"}, Value: 1.5, Timestamp: 1000},
+		{Metric: model.Metric{"job": "api", "code": "500"}, Histogram: hist, Timestamp: 2000},
+	}
+
+	got, err := Decode[request](v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []request{
+		{Job: "api", Code: 200, Path: "/", Rate: 1.5, At: time.Unix(1, 0), Labels: v[0].Metric},
+		{Job: "api", Code: 500, Hist: hist, At: time.Unix(2, 0), Labels: v[1].Metric},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %+v, want %+v", got, expected)
+	}
+
+	// Strict mode rejects histogram samples for the non-optional value.
+	if _, err := Decode[request](v, WithStrict()); err == nil || !strings.Contains(err.Error(), "histogram sample") {
+		t.Errorf("got error %v, want histogram sample error", err)
+	}
+}
+
+func TestDecodeMatrixAndScalar(t *testing.T) {
+	type point struct {
+		Instance string     `prom:"instance"`
+		Value    int        `prom:",value"`
+		At       model.Time `prom:",timestamp"`
+	}
+	m := model.Matrix{{
+		Metric: model.Metric{"instance": "a"},
+		Values: []model.SamplePair{{Timestamp: 1, Value: 1}, {Timestamp: 2, Value: 2}},
+	}}
+	got, err := Decode[point](m, WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []point{{"a", 1, 1}, {"a", 2, 2}}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %+v, want %+v", got, expected)
+	}
+
+	m[0].Values[1].Value = 2.5
+	if _, err := Decode[point](m, WithStrict()); err == nil || !strings.Contains(err.Error(), "not an integer") {
+		t.Errorf("got error %v, want non-integer error", err)
+	}
+
+	got, err = Decode[point](&model.Scalar{Value: 3, Timestamp: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []point{{"", 3, 4}}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %+v, want %+v", got, expected)
+	}
+	if _, err := Decode[point](&model.Scalar{Value: 3, Timestamp: 4}, WithStrict()); err == nil || !strings.Contains(err.Error(), `no label "instance"`) {
+		t.Errorf("got error %v, want missing label error", err)
+	}
+}
+
+func TestDecodeInvalidTypes(t *testing.T) {
+	type badTag struct {
+		V float64 `prom:"job,value"`
+	}
+	type badType struct {
+		V []string `prom:"job"`
+	}
+	type badLabel struct {
+		Code int `prom:"code"`
+	}
+	s := &model.Sample{Metric: model.Metric{"code": "abc"}}
+
+	if _, err := DecodeSample[badTag](s); err == nil {
+		t.Error("expected error for label name combined with value")
+	}
+	if _, err := DecodeSample[badType](s); err == nil {
+		t.Error("expected error for unsupported field type")
+	}
+	if _, err := DecodeSample[string](s); err == nil {
+		t.Error("expected error for non-struct type")
+	}
+	if _, err := DecodeSample[badLabel](s); err == nil {
+		t.Error("expected error for unparsable label value")
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.23
+
+package decode
+
+import (
+	"iter"
+	"reflect"
+
+	"github.com/prometheus/common/model"
+)
+
+// Samples returns an iterator over the samples of the provided query result,
+// each decoded into T as described for Decode. If decoding fails, the error
+// is yielded along with the zero value of T and iteration stops.
+func Samples[T any](v model.Value, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		d, err := newDecoder[T](opts)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		switch v := v.(type) {
+		case model.Matrix:
+			for _, ss := range v {
+				stopped := false
+				err := d.decodeStream(ss, func(t T) bool {
+					stopped = !yield(t, nil)
+					return !stopped
+				})
+				if err != nil {
+					yield(zero, err)
+					return
+				}
+				if stopped {
+					return
+				}
+			}
+		default:
+			ts, err := Decode[T](v, opts...)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, t := range ts {
+				if !yield(t, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Stream is a single series of a range query result. Its labels are decoded
+// into Series, leaving value, histogram, and timestamp fields alone.
+type Stream[T any] struct {
+	Series T
+	stream *model.SampleStream
+	d      *decoder[T]
+}
+
+// Floats returns an iterator over the float samples of the series.
+func (s Stream[T]) Floats() iter.Seq2[model.Time, model.SampleValue] {
+	return func(yield func(model.Time, model.SampleValue) bool) {
+		for _, p := range s.stream.Values {
+			if !yield(p.Timestamp, p.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Histograms returns an iterator over the native histogram samples of the
+// series.
+func (s Stream[T]) Histograms() iter.Seq2[model.Time, *model.SampleHistogram] {
+	return func(yield func(model.Time, *model.SampleHistogram) bool) {
+		for _, p := range s.stream.Histograms {
+			if !yield(p.Timestamp, p.Histogram) {
+				return
+			}
+		}
+	}
+}
+
+// Samples returns an iterator over all samples of the series, float samples
+// first, each decoded into T as described for Decode.
+func (s Stream[T]) Samples() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		stopped := false
+		err := s.d.decodeStream(s.stream, func(t T) bool {
+			stopped = !yield(t, nil)
+			return !stopped
+		})
+		if err != nil && !stopped {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// Series returns an iterator over the series of the provided range query
+// result. If decoding the labels of a series fails, the error is yielded
+// along with a zero Stream and iteration stops.
+func Series[T any](m model.Matrix, opts ...Option) iter.Seq2[Stream[T], error] {
+	return func(yield func(Stream[T], error) bool) {
+		d, err := newDecoder[T](opts)
+		if err != nil {
+			yield(Stream[T]{}, err)
+			return
+		}
+		for _, ss := range m {
+			s := Stream[T]{stream: ss, d: d}
+			if err := d.plan.decodeLabels(reflect.ValueOf(&s.Series).Elem(), ss.Metric, d.opts.strict); err != nil {
+				yield(Stream[T]{}, err)
+				return
+			}
+			if !yield(s, nil) {
+				return
+			}
+		}
+	}
+}
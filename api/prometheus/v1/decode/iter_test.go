@@ -0,0 +1,111 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.23
+
+package decode
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+type instanceSample struct {
+	Instance string     `prom:"instance"`
+	Value    float64    `prom:",value"`
+	At       model.Time `prom:",timestamp"`
+}
+
+var iterMatrix = model.Matrix{
+	{
+		Metric: model.Metric{"instance": "a"},
+		Values: []model.SamplePair{{Timestamp: 1, Value: 1}, {Timestamp: 2, Value: 2}},
+	},
+	{
+		Metric: model.Metric{"instance": "b"},
+		Values: []model.SamplePair{{Timestamp: 1, Value: 3}},
+	},
+	{
+		Metric: model.Metric{"job": "c"},
+		Values: []model.SamplePair{{Timestamp: 1, Value: 4}},
+	},
+}
+
+func TestSamples(t *testing.T) {
+	var sum float64
+	for s, err := range Samples[instanceSample](iterMatrix[:2]) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += s.Value
+	}
+	if sum != 6 {
+		t.Errorf("got sum %v, want 6", sum)
+	}
+
+	// Stops early.
+	n := 0
+	for range Samples[instanceSample](iterMatrix) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("got %d iterations, want 1", n)
+	}
+
+	var lastErr error
+	n = 0
+	for _, err := range Samples[instanceSample](iterMatrix, WithStrict()) {
+		n++
+		lastErr = err
+	}
+	if n != 4 || lastErr == nil {
+		t.Errorf("got %d iterations ending with error %v, want 4 ending with an error", n, lastErr)
+	}
+}
+
+func TestSeries(t *testing.T) {
+	var instances []string
+	var samples int
+	for s, err := range Series[instanceSample](iterMatrix) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		instances = append(instances, s.Series.Instance)
+		for ts, v := range s.Floats() {
+			if ts == 0 || v == 0 {
+				t.Errorf("unexpected sample %v @%v", v, ts)
+			}
+			samples++
+		}
+		for d, err := range s.Samples() {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Instance != s.Series.Instance {
+				t.Errorf("got instance %q, want %q", d.Instance, s.Series.Instance)
+			}
+		}
+	}
+	if len(instances) != 3 || instances[2] != "" || samples != 4 {
+		t.Errorf("got instances %q and %d samples", instances, samples)
+	}
+
+	for _, err := range Series[instanceSample](iterMatrix, WithStrict()) {
+		if err != nil {
+			return
+		}
+	}
+	t.Error("expected error for missing label in strict mode")
+}
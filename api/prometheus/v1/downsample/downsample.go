@@ -0,0 +1,376 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package downsample reduces the number of samples in the results of QueryRange
+// calls of the Prometheus HTTP API for rendering, e.g. to serve smaller
+// payloads to dashboards that cannot display thousands of points per series
+// anyway.
+//
+// Each series is downsampled independently to a target number of points with
+// one of the following methods:
+//
+//   - LTTB (Largest-Triangle-Three-Buckets) selects the samples that preserve
+//     the visual shape of the series best.
+//   - MinMax selects the minimum and maximum sample of each bucket of
+//     consecutive samples, which preserves spikes.
+//   - Average replaces each bucket of consecutive samples by their average.
+//
+// Gaps in a series, i.e. time ranges without samples, are preserved: The
+// samples on either side of a gap are downsampled separately, so that no point
+// is placed within the gap. With LTTB, the first and last sample of each
+// contiguous segment are always kept.
+//
+// Native histogram samples are downsampled separately from float samples of
+// the same series. LTTB and MinMax select whole histograms, using their count
+// as the value. Average averages the count, the sum, and each bucket.
+package downsample
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Method is a downsampling algorithm.
+type Method int
+
+// The supported downsampling methods, see the package documentation.
+const (
+	LTTB Method = iota
+	MinMax
+	Average
+)
+
+// Opts configures downsampling.
+type Opts struct {
+	// Method is the downsampling algorithm, LTTB by default.
+	Method Method
+	// Points is the target number of points per series. Series with at
+	// most Points samples are left alone. As each contiguous segment of a
+	// series gets at least two points (see MaxGap), a series with many gaps
+	// may end up with more points. If Points is zero or negative, nothing
+	// is downsampled.
+	Points int
+	// MaxGap is the maximum distance of two consecutive samples within a
+	// contiguous segment of a series. If zero, it is 1.5 times the smallest
+	// distance of consecutive samples in the series, which is usually the
+	// step of the range query.
+	MaxGap time.Duration
+}
+
+// Matrix downsamples each series in m as configured by opts. The returned
+// matrix shares the metrics of its series with m, as well as the samples of
+// series that are not downsampled. m itself is not modified.
+func Matrix(m model.Matrix, opts Opts) model.Matrix {
+	result := make(model.Matrix, 0, len(m))
+	for _, ss := range m {
+		result = append(result, &model.SampleStream{
+			Metric:     ss.Metric,
+			Values:     Floats(ss.Values, opts),
+			Histograms: Histograms(ss.Histograms, opts),
+		})
+	}
+	return result
+}
+
+// Floats downsamples float samples, which must be sorted by timestamp, as
+// configured by opts. If nothing is downsampled, values is returned as is.
+func Floats(values []model.SamplePair, opts Opts) []model.SamplePair {
+	if opts.Points <= 0 || len(values) <= opts.Points {
+		return values
+	}
+	s := series{
+		n:  len(values),
+		ts: func(i int) model.Time { return values[i].Timestamp },
+		v:  func(i int) float64 { return float64(values[i].Value) },
+	}
+	result := make([]model.SamplePair, 0, opts.Points)
+	s.downsample(opts, func(indices []int) {
+		if opts.Method == Average {
+			var sum float64
+			for _, i := range indices {
+				sum += float64(values[i].Value)
+			}
+			result = append(result, model.SamplePair{
+				Timestamp: s.meanTimestamp(indices),
+				Value:     model.SampleValue(sum / float64(len(indices))),
+			})
+			return
+		}
+		for _, i := range indices {
+			result = append(result, values[i])
+		}
+	})
+	return result
+}
+
+// Histograms downsamples native histogram samples, which must be sorted by
+// timestamp, as configured by opts. If nothing is downsampled, histograms is
+// returned as is.
+func Histograms(histograms []model.SampleHistogramPair, opts Opts) []model.SampleHistogramPair {
+	if opts.Points <= 0 || len(histograms) <= opts.Points {
+		return histograms
+	}
+	s := series{
+		n:  len(histograms),
+		ts: func(i int) model.Time { return histograms[i].Timestamp },
+		v: func(i int) float64 {
+			if histograms[i].Histogram == nil {
+				return math.NaN()
+			}
+			return float64(histograms[i].Histogram.Count)
+		},
+	}
+	result := make([]model.SampleHistogramPair, 0, opts.Points)
+	s.downsample(opts, func(indices []int) {
+		if opts.Method == Average {
+			result = append(result, model.SampleHistogramPair{
+				Timestamp: s.meanTimestamp(indices),
+				Histogram: averageHistogram(histograms, indices),
+			})
+			return
+		}
+		for _, i := range indices {
+			result = append(result, histograms[i])
+		}
+	})
+	return result
+}
+
+// series provides access to the timestamps and values of the n samples of a
+// series.
+type series struct {
+	n  int
+	ts func(i int) model.Time
+	v  func(i int) float64
+}
+
+// downsample splits the series into contiguous segments and calls emit for
+// each resulting point, with the indices of the samples to emit (LTTB and
+// MinMax) or to average (Average), in order.
+func (s series) downsample(opts Opts, emit func(indices []int)) {
+	segments := s.segments(opts.MaxGap)
+	for _, seg := range segments {
+		start, end := seg[0], seg[1]
+		n := end - start
+		// Distribute the points proportionally to the segment lengths,
+		// but keep at least the first and the last sample.
+		points := int(math.Round(float64(opts.Points) * float64(n) / float64(s.n)))
+		if points < 2 {
+			points = 2
+		}
+		if points >= n {
+			for i := start; i < end; i++ {
+				emit([]int{i})
+			}
+			continue
+		}
+		switch opts.Method {
+		case Average:
+			for b := 0; b < points; b++ {
+				bStart, bEnd := start+b*n/points, start+(b+1)*n/points
+				indices := make([]int, 0, bEnd-bStart)
+				for i := bStart; i < bEnd; i++ {
+					indices = append(indices, i)
+				}
+				emit(indices)
+			}
+		case MinMax:
+			emit(s.minMax(start, end, points))
+		default:
+			emit(s.lttb(start, end, points))
+		}
+	}
+}
+
+// segments returns the start (inclusive) and end (exclusive) indices of the
+// contiguous segments of the series.
+func (s series) segments(maxGap time.Duration) [][2]int {
+	if s.n == 0 {
+		return nil
+	}
+	gap := model.Time(maxGap / time.Millisecond)
+	if gap <= 0 {
+		minDist := model.Time(-1)
+		for i := 1; i < s.n; i++ {
+			if d := s.ts(i) - s.ts(i-1); d > 0 && (minDist < 0 || d < minDist) {
+				minDist = d
+			}
+		}
+		if minDist < 0 {
+			// All samples have the same timestamp.
+			return [][2]int{{0, s.n}}
+		}
+		gap = minDist + minDist/2
+	}
+	var (
+		segments [][2]int
+		start    int
+	)
+	for i := 1; i < s.n; i++ {
+		if s.ts(i)-s.ts(i-1) > gap {
+			segments = append(segments, [2]int{start, i})
+			start = i
+		}
+	}
+	return append(segments, [2]int{start, s.n})
+}
+
+// lttb selects the indices of the points in [start, end) with the
+// Largest-Triangle-Three-Buckets algorithm. 2 <= points < end-start.
+func (s series) lttb(start, end, points int) []int {
+	indices := make([]int, 0, points)
+	indices = append(indices, start)
+	// x returns the timestamp relative to the segment start to avoid
+	// precision issues with large timestamps.
+	x := func(i int) float64 { return float64(s.ts(i) - s.ts(start)) }
+
+	// The first and the last point are fixed, the others are selected
+	// from buckets of equal size.
+	bucketSize := float64(end-start-2) / float64(points-2)
+	a := start
+	for b := 0; b < points-2; b++ {
+		// Average of the next bucket (or the last point).
+		nextStart := start + 1 + int(float64(b+1)*bucketSize)
+		nextEnd := start + 1 + int(float64(b+2)*bucketSize)
+		if nextEnd > end {
+			nextEnd = end
+		}
+		var avgX, avgY float64
+		var count int
+		for i := nextStart; i < nextEnd; i++ {
+			if y := s.v(i); !math.IsNaN(y) {
+				avgX += x(i)
+				avgY += y
+				count++
+			}
+		}
+		if count > 0 {
+			avgX /= float64(count)
+			avgY /= float64(count)
+		} else {
+			avgX, avgY = x(end-1), s.v(end-1)
+		}
+
+		// Select the point of the current bucket forming the largest
+		// triangle with the previously selected point and the average.
+		bStart := start + 1 + int(float64(b)*bucketSize)
+		bEnd := start + 1 + int(float64(b+1)*bucketSize)
+		selected, maxArea := bStart, -1.0
+		ax, ay := x(a), s.v(a)
+		for i := bStart; i < bEnd; i++ {
+			area := math.Abs((ax-avgX)*(s.v(i)-ay) - (ax-x(i))*(avgY-ay))
+			if area > maxArea {
+				selected, maxArea = i, area
+			}
+		}
+		indices = append(indices, selected)
+		a = selected
+	}
+	return append(indices, end-1)
+}
+
+// minMax selects the indices of the minimum and maximum of points/2 buckets in
+// [start, end), in order. 2 <= points < end-start.
+func (s series) minMax(start, end, points int) []int {
+	n := end - start
+	buckets := points / 2
+	indices := make([]int, 0, points)
+	for b := 0; b < buckets; b++ {
+		bStart, bEnd := start+b*n/buckets, start+(b+1)*n/buckets
+		minIdx, maxIdx := -1, -1
+		for i := bStart; i < bEnd; i++ {
+			v := s.v(i)
+			if math.IsNaN(v) {
+				continue
+			}
+			if minIdx < 0 || v < s.v(minIdx) {
+				minIdx = i
+			}
+			if maxIdx < 0 || v > s.v(maxIdx) {
+				maxIdx = i
+			}
+		}
+		switch {
+		case minIdx < 0:
+			// Only NaN values, keep the first.
+			indices = append(indices, bStart)
+		case minIdx == maxIdx:
+			indices = append(indices, minIdx)
+		case minIdx < maxIdx:
+			indices = append(indices, minIdx, maxIdx)
+		default:
+			indices = append(indices, maxIdx, minIdx)
+		}
+	}
+	return indices
+}
+
+// meanTimestamp returns the mean timestamp of the samples with the provided
+// indices.
+func (s series) meanTimestamp(indices []int) model.Time {
+	first := s.ts(indices[0])
+	var sum int64
+	for _, i := range indices {
+		sum += int64(s.ts(i) - first)
+	}
+	return first + model.Time(sum/int64(len(indices)))
+}
+
+// averageHistogram returns the average of the histograms with the provided
+// indices. Buckets are matched by their boundaries.
+func averageHistogram(histograms []model.SampleHistogramPair, indices []int) *model.SampleHistogram {
+	type bucketKey struct {
+		boundaries   int32
+		lower, upper model.FloatString
+	}
+	var (
+		result  = &model.SampleHistogram{}
+		buckets = map[bucketKey]model.FloatString{}
+		n       model.FloatString
+	)
+	for _, i := range indices {
+		h := histograms[i].Histogram
+		if h == nil {
+			continue
+		}
+		n++
+		result.Count += h.Count
+		result.Sum += h.Sum
+		for _, b := range h.Buckets {
+			buckets[bucketKey{b.Boundaries, b.Lower, b.Upper}] += b.Count
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	result.Count /= n
+	result.Sum /= n
+	for k, count := range buckets {
+		result.Buckets = append(result.Buckets, &model.HistogramBucket{
+			Boundaries: k.boundaries,
+			Lower:      k.lower,
+			Upper:      k.upper,
+			Count:      count / n,
+		})
+	}
+	sort.Slice(result.Buckets, func(i, j int) bool {
+		if result.Buckets[i].Lower != result.Buckets[j].Lower {
+			return result.Buckets[i].Lower < result.Buckets[j].Lower
+		}
+		return result.Buckets[i].Upper < result.Buckets[j].Upper
+	})
+	return result
+}
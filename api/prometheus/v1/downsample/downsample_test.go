@@ -0,0 +1,144 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downsample
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+// makeFloats returns samples at the provided offsets (in steps of 15s) with
+// values from fn.
+func makeFloats(offsets []int, fn func(i int) float64) []model.SamplePair {
+	values := make([]model.SamplePair, 0, len(offsets))
+	for _, o := range offsets {
+		values = append(values, model.SamplePair{
+			Timestamp: model.Time(o * 15000),
+			Value:     model.SampleValue(fn(o)),
+		})
+	}
+	return values
+}
+
+func rangeOffsets(start, end int) []int {
+	var offsets []int
+	for i := start; i < end; i++ {
+		offsets = append(offsets, i)
+	}
+	return offsets
+}
+
+func TestFloatsLTTB(t *testing.T) {
+	// A sine wave with a single spike, which must be preserved.
+	values := makeFloats(rangeOffsets(0, 1000), func(i int) float64 {
+		if i == 500 {
+			return 100
+		}
+		return math.Sin(float64(i) / 50)
+	})
+	got := Floats(values, Opts{Points: 50})
+	if len(got) != 50 {
+		t.Fatalf("got %d points, want 50", len(got))
+	}
+	if got[0] != values[0] || got[len(got)-1] != values[len(values)-1] {
+		t.Error("first or last point not preserved")
+	}
+	var spike bool
+	for i, p := range got {
+		if p.Value == 100 {
+			spike = true
+		}
+		if i > 0 && p.Timestamp <= got[i-1].Timestamp {
+			t.Errorf("points not in order at %d", i)
+		}
+	}
+	if !spike {
+		t.Error("spike not preserved")
+	}
+}
+
+func TestFloatsGaps(t *testing.T) {
+	// Two segments of 100 samples with a gap of 50 steps.
+	values := makeFloats(append(rangeOffsets(0, 100), rangeOffsets(150, 250)...), func(i int) float64 { return float64(i) })
+
+	for _, method := range []Method{LTTB, MinMax, Average} {
+		got := Floats(values, Opts{Method: method, Points: 20})
+		if len(got) != 20 {
+			t.Errorf("method %d: got %d points, want 20", method, len(got))
+		}
+		for _, p := range got {
+			if p.Timestamp > 99*15000 && p.Timestamp < 150*15000 {
+				t.Errorf("method %d: point %v within gap", method, p)
+			}
+		}
+	}
+}
+
+func TestFloatsMinMax(t *testing.T) {
+	values := makeFloats(rangeOffsets(0, 8), func(i int) float64 { return []float64{3, 1, 4, 1, 5, 9, 2, 6}[i] })
+	got := Floats(values, Opts{Method: MinMax, Points: 4})
+	// Buckets [3 1 4 1] and [5 9 2 6].
+	want := []model.SamplePair{values[1], values[2], values[5], values[6]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestHistogramsAverage(t *testing.T) {
+	hist := func(count float64) *model.SampleHistogram {
+		return &model.SampleHistogram{
+			Count: model.FloatString(count),
+			Sum:   model.FloatString(2 * count),
+			Buckets: model.HistogramBuckets{
+				{Boundaries: 0, Lower: 0, Upper: 1, Count: model.FloatString(count)},
+			},
+		}
+	}
+	var histograms []model.SampleHistogramPair
+	for i := 0; i < 4; i++ {
+		histograms = append(histograms, model.SampleHistogramPair{Timestamp: model.Time(i * 1000), Histogram: hist(float64(i))})
+	}
+	got := Histograms(histograms, Opts{Method: Average, Points: 2})
+	want := []model.SampleHistogramPair{
+		{Timestamp: 500, Histogram: hist(0.5)},
+		{Timestamp: 2500, Histogram: hist(2.5)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// LTTB keeps whole histograms.
+	got = Histograms(histograms, Opts{Points: 3})
+	if len(got) != 3 || got[0].Histogram != histograms[0].Histogram || got[2].Histogram != histograms[3].Histogram {
+		t.Errorf("unexpected LTTB result %v", got)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	values := makeFloats(rangeOffsets(0, 10), func(i int) float64 { return float64(i) })
+	m := model.Matrix{
+		{Metric: model.Metric{"job": "a"}, Values: values},
+		{Metric: model.Metric{"job": "b"}, Values: values[:3]},
+	}
+	got := Matrix(m, Opts{Points: 5})
+	if len(got[0].Values) != 5 || len(got[1].Values) != 3 || got[1].Metric["job"] != "b" {
+		t.Errorf("unexpected result %v", got)
+	}
+	if len(m[0].Values) != 10 {
+		t.Error("input modified")
+	}
+}
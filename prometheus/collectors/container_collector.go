@@ -0,0 +1,274 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectors
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ContainerCollectorOpts defines the behavior of a container info collector
+// created with NewContainerCollector. All paths are configurable to allow
+// testing with fixtures. Empty paths are replaced by the defaults documented
+// below.
+type ContainerCollectorOpts struct {
+	// If non-empty, the collected metric is prefixed by the provided string
+	// and an underscore ("_").
+	Namespace string
+	// CgroupPath is the path of the cgroup file of the process, by default
+	// "/proc/self/cgroup".
+	CgroupPath string
+	// MountInfoPath is the path of the mountinfo file of the process, by
+	// default "/proc/self/mountinfo". It is used as a fallback for the
+	// container ID if the cgroup file does not reveal it, e.g. with cgroup
+	// namespaces.
+	MountInfoPath string
+	// PodNamePath and PodNamespacePath are the paths of Downward API files
+	// containing the name and the namespace of the pod, by default
+	// "/etc/podinfo/name" and "/etc/podinfo/namespace". They have to be
+	// mounted with fieldRef "metadata.name" and "metadata.namespace",
+	// respectively.
+	PodNamePath      string
+	PodNamespacePath string
+	// ServiceAccountNamespacePath is the path of the namespace file of the
+	// mounted service account, by default
+	// "/var/run/secrets/kubernetes.io/serviceaccount/namespace". It is used
+	// as a fallback for the namespace of the pod if the file at
+	// PodNamespacePath does not exist.
+	ServiceAccountNamespacePath string
+	// If true, any error encountered during collection is reported as an
+	// invalid metric (see NewInvalidMetric). Otherwise, errors are ignored.
+	// Missing files are never an error, as they are expected outside of
+	// containers.
+	ReportErrors bool
+}
+
+// NewContainerCollector returns a collector that exposes the identity of the
+// container and Kubernetes pod the process runs in as a single metric
+// "container_info" with the constant value 1 and the following labels:
+//
+//   - "container_id" and "container_runtime" as derived from the cgroup
+//     file or, as a fallback, the mountinfo file of the process. The runtime
+//     is one of "docker", "containerd", "cri-o", and "podman", or empty if it
+//     cannot be determined.
+//   - "k8s_pod_uid" as derived from the cgroup path set up by the kubelet.
+//   - "k8s_pod_name" and "k8s_namespace" as read from Downward API files.
+//
+// The label names are chosen to not collide with the "pod" and "namespace"
+// target labels commonly attached by the Kubernetes service discovery of
+// Prometheus. Labels that cannot be determined are empty. If none can be
+// determined, e.g. because the process does not run in a container, the metric
+// is not collected at all.
+//
+// The files are read upon each collection. The collector only works on Linux.
+// On other operating systems, it will not collect any metrics.
+func NewContainerCollector(opts ContainerCollectorOpts) prometheus.Collector {
+	defaultPath := func(path *string, def string) {
+		if *path == "" {
+			*path = def
+		}
+	}
+	defaultPath(&opts.CgroupPath, "/proc/self/cgroup")
+	defaultPath(&opts.MountInfoPath, "/proc/self/mountinfo")
+	defaultPath(&opts.PodNamePath, "/etc/podinfo/name")
+	defaultPath(&opts.PodNamespacePath, "/etc/podinfo/namespace")
+	defaultPath(&opts.ServiceAccountNamespacePath, "/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+
+	return &containerCollector{
+		opts: opts,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, "", "container_info"),
+			"Identity of the container and Kubernetes pod the process runs in. Value is always 1.",
+			[]string{"container_id", "container_runtime", "k8s_pod_uid", "k8s_pod_name", "k8s_namespace"},
+			nil,
+		),
+	}
+}
+
+type containerCollector struct {
+	opts ContainerCollectorOpts
+	desc *prometheus.Desc
+}
+
+// containerIdentity is the information collected by the containerCollector.
+type containerIdentity struct {
+	containerID, runtime, podUID, podName, namespace string
+}
+
+// Describe implements prometheus.Collector.
+func (c *containerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *containerCollector) Collect(ch chan<- prometheus.Metric) {
+	id, err := c.identity()
+	if err != nil && c.opts.ReportErrors {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+	}
+	if id == (containerIdentity{}) {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.desc, prometheus.GaugeValue, 1,
+		id.containerID, id.runtime, id.podUID, id.podName, id.namespace,
+	)
+}
+
+// identity reads all configured files. It returns as much of the identity as
+// possible, together with all errors encountered other than missing files.
+func (c *containerCollector) identity() (containerIdentity, error) {
+	var (
+		id   containerIdentity
+		errs []error
+	)
+	if lines, err := readLines(c.opts.CgroupPath); err == nil {
+		id.containerID, id.runtime, id.podUID = parseCgroup(lines)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, err)
+	}
+	if id.containerID == "" || id.podUID == "" {
+		if lines, err := readLines(c.opts.MountInfoPath); err == nil {
+			containerID, runtime, podUID := parseMountInfo(lines)
+			if id.containerID == "" {
+				id.containerID, id.runtime = containerID, runtime
+			}
+			if id.podUID == "" {
+				id.podUID = podUID
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	readFirst := func(paths ...string) string {
+		for _, path := range paths {
+			b, err := os.ReadFile(path)
+			if err == nil {
+				return strings.TrimSpace(string(b))
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+		return ""
+	}
+	id.podName = readFirst(c.opts.PodNamePath)
+	id.namespace = readFirst(c.opts.PodNamespacePath, c.opts.ServiceAccountNamespacePath)
+	return id, errors.Join(errs...)
+}
+
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+var (
+	// cgroupContainerRE matches the last path segment of a container
+	// cgroup, e.g. "cri-containerd-<id>.scope" with the systemd cgroup
+	// driver or "<id>" with the cgroupfs driver.
+	cgroupContainerRE = regexp.MustCompile(`^(?:(docker|cri-containerd|crio|libpod)-)?([0-9a-f]{64})(?:\.scope)?$`)
+	// podUIDRE matches the pod UID in a kubepods cgroup path. The systemd
+	// cgroup driver replaces the dashes of the UID with underscores.
+	podUIDRE = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+	// mountInfoContainerRE matches the container directories of Docker and
+	// CRI-O or Podman, which contain e.g. the hostname file bind-mounted
+	// into the container.
+	mountInfoContainerRE = regexp.MustCompile(`/(docker/containers|containers/storage/overlay-containers)/([0-9a-f]{64})/`)
+	// mountInfoPodRE matches the pod directory of the kubelet, which
+	// contains e.g. the volumes mounted into the container.
+	mountInfoPodRE = regexp.MustCompile(`/kubelet/pods/([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})/`)
+)
+
+// cgroupRuntimes maps the prefixes of container cgroups to container runtimes.
+var cgroupRuntimes = map[string]string{
+	"docker":         "docker",
+	"cri-containerd": "containerd",
+	"crio":           "cri-o",
+	"libpod":         "podman",
+}
+
+// parseCgroup derives the container ID, the container runtime, and the pod UID
+// from the lines of a /proc/<pid>/cgroup file, which are of the form
+// "<hierarchy>:<controllers>:<path>".
+func parseCgroup(lines []string) (containerID, runtime, podUID string) {
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		path := parts[2]
+		segments := strings.Split(path, "/")
+		for i := len(segments) - 1; i >= 0 && containerID == ""; i-- {
+			m := cgroupContainerRE.FindStringSubmatch(segments[i])
+			if m == nil {
+				continue
+			}
+			containerID, runtime = m[2], cgroupRuntimes[m[1]]
+			if runtime == "" && i > 0 && segments[i-1] == "docker" {
+				// The cgroupfs driver of Docker uses /docker/<id>.
+				runtime = "docker"
+			}
+		}
+		if podUID == "" {
+			if m := podUIDRE.FindStringSubmatch(path); m != nil {
+				podUID = strings.ReplaceAll(m[1], "_", "-")
+			}
+		}
+		if containerID != "" && podUID != "" {
+			break
+		}
+	}
+	return containerID, runtime, podUID
+}
+
+// parseMountInfo derives the container ID, the container runtime, and the pod
+// UID from the sources of the mounts in the lines of a /proc/<pid>/mountinfo
+// file.
+func parseMountInfo(lines []string) (containerID, runtime, podUID string) {
+	for _, line := range lines {
+		if containerID == "" {
+			if m := mountInfoContainerRE.FindStringSubmatch(line); m != nil {
+				containerID = m[2]
+				if m[1] == "docker/containers" {
+					runtime = "docker"
+				}
+				// The overlay-containers directory is shared by CRI-O
+				// and Podman, so the runtime is left empty.
+			}
+		}
+		if podUID == "" {
+			if m := mountInfoPodRE.FindStringSubmatch(line); m != nil {
+				podUID = m[1]
+			}
+		}
+	}
+	return containerID, runtime, podUID
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const (
+	testContainerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testPodUID      = "7c9a3b52-1f0e-4c57-9d3c-2b8f6e4a1d90"
+)
+
+func TestContainerCollector(t *testing.T) {
+	for name, tc := range map[string]struct {
+		files    map[string]string
+		expected string
+	}{
+		"cgroup v2 with systemd driver and Downward API": {
+			files: map[string]string{
+				"cgroup":    "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + strings.ReplaceAll(testPodUID, "-", "_") + ".slice/cri-containerd-" + testContainerID + ".scope\n",
+				"name":      "web-0\n",
+				"namespace": "shop\n",
+			},
+			expected: `container_id="` + testContainerID + `",container_runtime="containerd",k8s_namespace="shop",k8s_pod_name="web-0",k8s_pod_uid="` + testPodUID + `"`,
+		},
+		"cgroup v1 with cgroupfs driver and service account": {
+			files: map[string]string{
+				"cgroup": "12:pids:/kubepods/besteffort/pod" + testPodUID + "/" + testContainerID + "\n" +
+					"11:memory:/kubepods/besteffort/pod" + testPodUID + "/" + testContainerID + "\n",
+				"sa_namespace": "kube-system",
+			},
+			expected: `container_id="` + testContainerID + `",container_runtime="",k8s_namespace="kube-system",k8s_pod_name="",k8s_pod_uid="` + testPodUID + `"`,
+		},
+		"plain Docker": {
+			files: map[string]string{
+				"cgroup": "0::/system.slice/docker-" + testContainerID + ".scope\n",
+			},
+			expected: `container_id="` + testContainerID + `",container_runtime="docker",k8s_namespace="",k8s_pod_name="",k8s_pod_uid=""`,
+		},
+		"cgroup namespace with mountinfo fallback": {
+			files: map[string]string{
+				"cgroup": "0::/\n",
+				"mountinfo": "100 90 0:52 / / rw,relatime - overlay overlay rw\n" +
+					"101 100 254:1 /var/lib/docker/containers/" + testContainerID + "/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw\n" +
+					"102 100 254:1 /var/lib/kubelet/pods/" + testPodUID + "/volumes/kubernetes.io~empty-dir/data /data rw - ext4 /dev/vda1 rw\n",
+			},
+			expected: `container_id="` + testContainerID + `",container_runtime="docker",k8s_namespace="",k8s_pod_name="",k8s_pod_uid="` + testPodUID + `"`,
+		},
+		"not in a container": {
+			files: map[string]string{
+				"cgroup": "0::/user.slice/user-1000.slice/session-1.scope\n",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			reg := prometheus.NewPedanticRegistry()
+			reg.MustRegister(NewContainerCollector(ContainerCollectorOpts{
+				CgroupPath:                  filepath.Join(dir, "cgroup"),
+				MountInfoPath:               filepath.Join(dir, "mountinfo"),
+				PodNamePath:                 filepath.Join(dir, "name"),
+				PodNamespacePath:            filepath.Join(dir, "namespace"),
+				ServiceAccountNamespacePath: filepath.Join(dir, "sa_namespace"),
+				ReportErrors:                true,
+			}))
+
+			var expected string
+			if tc.expected != "" {
+				expected = `
+# HELP container_info Identity of the container and Kubernetes pod the process runs in. Value is always 1.
+# TYPE container_info gauge
+container_info{` + tc.expected + `} 1
+`
+			}
+			if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -51,6 +51,9 @@ import "github.com/prometheus/client_golang/prometheus"
 // sample values.
 //
 // Anything that does not fit into the scheme above is silently ignored.
+//
+// For the reverse direction, i.e. exposing Prometheus metrics in the expvar
+// format, see the promexpvar package.
 func NewExpvarCollector(exports map[string]*prometheus.Desc) prometheus.Collector {
 	//nolint:staticcheck // Ignore SA1019 until v2.
 	return prometheus.NewExpvarCollector(exports)
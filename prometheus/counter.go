@@ -74,8 +74,9 @@ type CounterVecOpts struct {
 
 // NewCounter creates a new Counter based on the provided CounterOpts.
 //
-// The returned implementation also implements ExemplarAdder. It is safe to
-// perform the corresponding type assertion.
+// The returned implementation also implements ExemplarAdder and
+// LabelSetExemplarAdder. It is safe to perform the corresponding type
+// assertions.
 //
 // The returned implementation tracks the counter value in two separate
 // variables, a float64 and a uint64. The latter is used to track calls of the
@@ -89,7 +90,7 @@ func NewCounter(opts CounterOpts) Counter {
 		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
 		opts.Help,
 		nil,
-		opts.ConstLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 	)
 	if opts.now == nil {
 		opts.now = time.Now
@@ -115,6 +116,8 @@ type counter struct {
 	labelPairs []*dto.LabelPair
 	exemplar   atomic.Value // Containing nil or a *dto.Exemplar.
 
+	trackedMemory
+
 	// now is for testing purposes, by default it's time.Now.
 	now func() time.Time
 }
@@ -148,6 +151,15 @@ func (c *counter) AddWithExemplar(v float64, e Labels) {
 	c.updateExemplar(v, e)
 }
 
+func (c *counter) AddWithExemplarLabelSet(v float64, ls LabelSet) {
+	c.Add(v)
+	e, err := newExemplarFromLabelSet(v, c.now(), ls)
+	if err != nil {
+		panic(err)
+	}
+	c.storeExemplar(e)
+}
+
 func (c *counter) Inc() {
 	atomic.AddUint64(&c.valInt, 1)
 }
@@ -177,7 +189,13 @@ func (c *counter) updateExemplar(v float64, l Labels) {
 	if err != nil {
 		panic(err)
 	}
-	c.exemplar.Store(e)
+	c.storeExemplar(e)
+}
+
+// storeExemplar replaces the exemplar of the counter with e.
+func (c *counter) storeExemplar(e *dto.Exemplar) {
+	old, _ := c.exemplar.Swap(e).(*dto.Exemplar)
+	c.trackExemplar(old, e)
 }
 
 // CounterVec is a Collector that bundles a set of Counters that all share the
@@ -204,7 +222,7 @@ func (v2) NewCounterVec(opts CounterVecOpts) *CounterVec {
 		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
 		opts.Help,
 		opts.VariableLabels,
-		opts.ConstLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 	)
 	if opts.now == nil {
 		opts.now = time.Now
@@ -298,6 +316,30 @@ func (v *CounterVec) With(labels Labels) Counter {
 	return c
 }
 
+// GetMetricWithLabelSet works like GetMetricWith, but with the labels provided
+// as LabelSet, which avoids allocations on hot paths.
+func (v *CounterVec) GetMetricWithLabelSet(ls LabelSet) (Counter, error) {
+	metric, err := v.MetricVec.GetMetricWithLabelSet(ls)
+	if metric != nil {
+		return metric.(Counter), err
+	}
+	return nil, err
+}
+
+// WithLabelSet works as GetMetricWithLabelSet, but panics where
+// GetMetricWithLabelSet would have returned an error. A LabelSet can be created
+// once and reused, like
+//
+//	notFound := prometheus.NewLabelSet("code", "404", "method", "GET")
+//	myVec.WithLabelSet(notFound).Add(42)
+func (v *CounterVec) WithLabelSet(ls LabelSet) Counter {
+	c, err := v.GetMetricWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
 // CurryWith returns a vector curried with the provided labels, i.e. the
 // returned vector has those labels pre-set for all labeled operations performed
 // on it. The cardinality of the curried vector is reduced accordingly. The
@@ -329,6 +371,26 @@ func (v *CounterVec) MustCurryWith(labels Labels) *CounterVec {
 	return vec
 }
 
+// CurryWithLabelSet works like CurryWith, but with the labels provided as
+// LabelSet.
+func (v *CounterVec) CurryWithLabelSet(ls LabelSet) (*CounterVec, error) {
+	vec, err := v.MetricVec.CurryWithLabelSet(ls)
+	if vec != nil {
+		return &CounterVec{vec}, err
+	}
+	return nil, err
+}
+
+// MustCurryWithLabelSet works as CurryWithLabelSet but panics where
+// CurryWithLabelSet would have returned an error.
+func (v *CounterVec) MustCurryWithLabelSet(ls LabelSet) *CounterVec {
+	vec, err := v.CurryWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return vec
+}
+
 // CounterFunc is a Counter whose value is determined at collect time by calling a
 // provided function.
 //
@@ -353,6 +415,6 @@ func NewCounterFunc(opts CounterOpts, function func() float64) CounterFunc {
 		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
 		opts.Help,
 		nil,
-		opts.ConstLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 	), CounterValue, function)
 }
@@ -0,0 +1,191 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// CounterCheckOpts bundles the options for a CounterCheckingGatherer.
+type CounterCheckOpts struct {
+	// Clamp controls what happens to a counter that decreased without a
+	// reset. If false (the default), the decreased value is returned as
+	// is. If true, the counter is clamped to the previously gathered value
+	// until it exceeds that value again.
+	Clamp bool
+	// ErrorLog, if not nil, is used to log each detected decrease.
+	ErrorLog interface {
+		Println(v ...interface{})
+	}
+}
+
+// CounterCheckingGatherer is a Gatherer that checks the counters gathered from
+// another Gatherer for monotonicity. Custom Collectors emitting counters with
+// MustNewConstMetric sometimes report decreasing values without an actual
+// reset, which Prometheus interprets as a reset, resulting in spikes in rate
+// calculations.
+//
+// The CounterCheckingGatherer remembers the value and the created timestamp of
+// each counter series from the previous Gather call. A counter whose value
+// decreased while its created timestamp stayed the same (including counters
+// without created timestamp) is counted in the meta counter
+// prometheus_counter_decreases_total, partitioned by the name of the metric
+// family. The CounterCheckingGatherer is itself a Collector exposing that meta
+// counter, so it has to be registered somewhere for the meta counter to be
+// exposed. Registering it with the wrapped Registry is fine.
+//
+// Only the transition to a lower value is counted, i.e. a counter that stays
+// below its previous value is counted once, not on each Gather call. With Clamp
+// set in the CounterCheckOpts, the returned metric families are copies of the
+// gathered ones where needed, so that the gathered ones are never modified.
+//
+// Use NewCounterCheckingGatherer to create instances.
+type CounterCheckingGatherer struct {
+	gatherer  Gatherer
+	opts      CounterCheckOpts
+	decreases *CounterVec
+
+	mtx  sync.Mutex // Protects last and serializes the checks.
+	last map[string]counterState
+}
+
+// counterState is the state of a counter series as of the last Gather call.
+type counterState struct {
+	raw     float64 // As gathered from the wrapped Gatherer.
+	value   float64 // As returned, i.e. possibly clamped.
+	created time.Time
+}
+
+// NewCounterCheckingGatherer returns a CounterCheckingGatherer wrapping g.
+func NewCounterCheckingGatherer(g Gatherer, opts CounterCheckOpts) *CounterCheckingGatherer {
+	return &CounterCheckingGatherer{
+		gatherer: g,
+		opts:     opts,
+		decreases: NewCounterVec(
+			CounterOpts{
+				Name: "prometheus_counter_decreases_total",
+				Help: "Total number of gathered counter values that decreased without a change of the created timestamp.",
+			},
+			[]string{"name"},
+		),
+		last: map[string]counterState{},
+	}
+}
+
+// Gather implements Gatherer. It returns the result of the wrapped Gatherer,
+// with decreased counters clamped if configured.
+func (c *CounterCheckingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := c.gatherer.Gather()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	current := make(map[string]counterState, len(c.last))
+	var (
+		key    strings.Builder
+		result = mfs // Replaced by a copy once a metric family is clamped.
+	)
+	for i, mf := range mfs {
+		if mf.GetType() != dto.MetricType_COUNTER {
+			continue
+		}
+		var clampedMF *dto.MetricFamily
+		for j, m := range mf.GetMetric() {
+			key.Reset()
+			key.WriteString(mf.GetName())
+			for _, lp := range m.GetLabel() {
+				key.WriteByte(model.SeparatorByte)
+				key.WriteString(lp.GetName())
+				key.WriteByte(model.SeparatorByte)
+				key.WriteString(lp.GetValue())
+			}
+			k := key.String()
+
+			raw := m.GetCounter().GetValue()
+			state := counterState{raw: raw, value: raw}
+			if ts := m.GetCounter().GetCreatedTimestamp(); ts != nil {
+				state.created = ts.AsTime()
+			}
+			if last, ok := c.last[k]; ok && state.created.Equal(last.created) {
+				if raw < last.raw {
+					c.decreases.WithLabelValues(mf.GetName()).Inc()
+					if c.opts.ErrorLog != nil {
+						c.opts.ErrorLog.Println(fmt.Sprintf(
+							"counter %s decreased from %v to %v without a reset",
+							metricString(mf.GetName(), m), last.raw, raw,
+						))
+					}
+				}
+				if c.opts.Clamp && raw < last.value {
+					if clampedMF == nil {
+						if len(result) > 0 && &result[0] == &mfs[0] {
+							result = append([]*dto.MetricFamily(nil), mfs...)
+						}
+						clampedMF = &dto.MetricFamily{
+							Name:   mf.Name,
+							Help:   mf.Help,
+							Type:   mf.Type,
+							Unit:   mf.Unit,
+							Metric: append([]*dto.Metric(nil), mf.GetMetric()...),
+						}
+						result[i] = clampedMF
+					}
+					clamped := proto.Clone(m).(*dto.Metric)
+					clamped.Counter.Value = proto.Float64(last.value)
+					clampedMF.Metric[j] = clamped
+					state.value = last.value
+				}
+			}
+			if math.IsNaN(raw) {
+				// A NaN value cannot be compared, so forget about the series.
+				continue
+			}
+			current[k] = state
+		}
+	}
+	// Series not gathered this time are forgotten, so that they are not
+	// compared with stale values once they show up again.
+	c.last = current
+	return result, err
+}
+
+// Describe implements Collector.
+func (c *CounterCheckingGatherer) Describe(ch chan<- *Desc) {
+	c.decreases.Describe(ch)
+}
+
+// Collect implements Collector.
+func (c *CounterCheckingGatherer) Collect(ch chan<- Metric) {
+	c.decreases.Collect(ch)
+}
+
+// metricString formats the metric name and labels of m in the text format.
+func metricString(name string, m *dto.Metric) string {
+	if len(m.GetLabel()) == 0 {
+		return name
+	}
+	pairs := make([]string, 0, len(m.GetLabel()))
+	for _, lp := range m.GetLabel() {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", lp.GetName(), lp.GetValue()))
+	}
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
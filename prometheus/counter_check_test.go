@@ -0,0 +1,133 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+type recordingLogger []string
+
+func (l *recordingLogger) Println(v ...interface{}) {
+	*l = append(*l, fmt.Sprint(v...))
+}
+
+func TestCounterCheckingGatherer(t *testing.T) {
+	var (
+		value   float64
+		created = time.Unix(1000, 0)
+		desc    = NewDesc("jobs_total", "help", []string{"queue"}, nil)
+	)
+	reg := NewRegistry()
+	reg.MustRegister(CollectorFunc(func(ch chan<- Metric) {
+		ch <- MustNewConstMetricWithCreatedTimestamp(desc, CounterValue, value, created, "a")
+	}))
+
+	for _, clamp := range []bool{false, true} {
+		t.Run(fmt.Sprintf("clamp=%v", clamp), func(t *testing.T) {
+			var log recordingLogger
+			g := NewCounterCheckingGatherer(reg, CounterCheckOpts{Clamp: clamp, ErrorLog: &log})
+
+			gather := func() float64 {
+				t.Helper()
+				mfs, err := g.Gather()
+				if err != nil {
+					t.Fatal(err)
+				}
+				return mfs[0].GetMetric()[0].GetCounter().GetValue()
+			}
+			decreases := func() float64 {
+				m := &dto.Metric{}
+				if err := g.decreases.WithLabelValues("jobs_total").Write(m); err != nil {
+					t.Fatal(err)
+				}
+				return m.GetCounter().GetValue()
+			}
+
+			value, created = 10, time.Unix(1000, 0)
+			gather()
+			value = 7
+			got := gather()
+			if want := map[bool]float64{false: 7, true: 10}[clamp]; got != want {
+				t.Errorf("got value %v after decrease, want %v", got, want)
+			}
+			if decreases() != 1 || len(log) != 1 {
+				t.Errorf("got %v decreases and log %q, want one each", decreases(), log)
+			}
+			if want := `counter jobs_total{queue="a"} decreased from 10 to 7 without a reset`; len(log) > 0 && log[0] != want {
+				t.Errorf("got log %q, want %q", log[0], want)
+			}
+
+			// Staying below the previous value is not another decrease.
+			value = 8
+			got = gather()
+			if want := map[bool]float64{false: 8, true: 10}[clamp]; got != want {
+				t.Errorf("got value %v while below previous value, want %v", got, want)
+			}
+			if decreases() != 1 || len(log) != 1 {
+				t.Errorf("got %v decreases and log %q, want one each", decreases(), log)
+			}
+			value = 12
+			if got := gather(); got != 12 {
+				t.Errorf("got value %v after exceeding previous value, want 12", got)
+			}
+
+			// A decrease with a new created timestamp is a reset.
+			value, created = 3, time.Unix(2000, 0)
+			if got := gather(); got != 3 {
+				t.Errorf("got value %v after reset, want 3", got)
+			}
+			value = 5
+			gather()
+			if decreases() != 1 {
+				t.Errorf("got %v decreases, want 1", decreases())
+			}
+		})
+	}
+}
+
+func TestCounterCheckingGathererDoesNotModifyGathered(t *testing.T) {
+	desc := NewDesc("jobs_total", "help", nil, nil)
+	var mfs []*dto.MetricFamily
+	g := NewCounterCheckingGatherer(GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return mfs, nil
+	}), CounterCheckOpts{Clamp: true})
+
+	for _, value := range []float64{10, 7} {
+		m := &dto.Metric{}
+		if err := MustNewConstMetric(desc, CounterValue, value).Write(m); err != nil {
+			t.Fatal(err)
+		}
+		mfs = []*dto.MetricFamily{{
+			Name:   proto.String("jobs_total"),
+			Type:   dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{m},
+		}}
+		got, err := g.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := got[0].GetMetric()[0].GetCounter().GetValue(); v != 10 {
+			t.Errorf("got value %v, want 10", v)
+		}
+	}
+	if v := mfs[0].GetMetric()[0].GetCounter().GetValue(); v != 7 {
+		t.Errorf("gathered metric family was modified, got value %v, want 7", v)
+	}
+}
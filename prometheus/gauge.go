@@ -80,7 +80,7 @@ func NewGauge(opts GaugeOpts) Gauge {
 		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
 		opts.Help,
 		nil,
-		opts.ConstLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 	)
 	result := &gauge{desc: desc, labelPairs: desc.constLabelPairs}
 	result.init(result) // Init self-collection.
@@ -162,7 +162,7 @@ func (v2) NewGaugeVec(opts GaugeVecOpts) *GaugeVec {
 		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
 		opts.Help,
 		opts.VariableLabels,
-		opts.ConstLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 	)
 	return &GaugeVec{
 		MetricVec: NewMetricVec(desc, func(lvs ...string) Metric {
@@ -252,6 +252,30 @@ func (v *GaugeVec) With(labels Labels) Gauge {
 	return g
 }
 
+// GetMetricWithLabelSet works like GetMetricWith, but with the labels provided
+// as LabelSet, which avoids allocations on hot paths.
+func (v *GaugeVec) GetMetricWithLabelSet(ls LabelSet) (Gauge, error) {
+	metric, err := v.MetricVec.GetMetricWithLabelSet(ls)
+	if metric != nil {
+		return metric.(Gauge), err
+	}
+	return nil, err
+}
+
+// WithLabelSet works as GetMetricWithLabelSet, but panics where
+// GetMetricWithLabelSet would have returned an error. A LabelSet can be created
+// once and reused, like
+//
+//	notFound := prometheus.NewLabelSet("code", "404", "method", "GET")
+//	myVec.WithLabelSet(notFound).Set(42)
+func (v *GaugeVec) WithLabelSet(ls LabelSet) Gauge {
+	g, err := v.GetMetricWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
 // CurryWith returns a vector curried with the provided labels, i.e. the
 // returned vector has those labels pre-set for all labeled operations performed
 // on it. The cardinality of the curried vector is reduced accordingly. The
@@ -283,6 +307,26 @@ func (v *GaugeVec) MustCurryWith(labels Labels) *GaugeVec {
 	return vec
 }
 
+// CurryWithLabelSet works like CurryWith, but with the labels provided as
+// LabelSet.
+func (v *GaugeVec) CurryWithLabelSet(ls LabelSet) (*GaugeVec, error) {
+	vec, err := v.MetricVec.CurryWithLabelSet(ls)
+	if vec != nil {
+		return &GaugeVec{vec}, err
+	}
+	return nil, err
+}
+
+// MustCurryWithLabelSet works as CurryWithLabelSet but panics where
+// CurryWithLabelSet would have returned an error.
+func (v *GaugeVec) MustCurryWithLabelSet(ls LabelSet) *GaugeVec {
+	vec, err := v.CurryWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return vec
+}
+
 // GaugeFunc is a Gauge whose value is determined at collect time by calling a
 // provided function.
 //
@@ -306,6 +350,6 @@ func NewGaugeFunc(opts GaugeOpts, function func() float64) GaugeFunc {
 		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
 		opts.Help,
 		nil,
-		opts.ConstLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 	), GaugeValue, function)
 }
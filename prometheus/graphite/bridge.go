@@ -17,11 +17,11 @@ package graphite
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"net"
 	"sort"
 	"time"
 
@@ -57,16 +57,22 @@ type Config struct {
 	// Whether to use Graphite tags or not. Defaults to false.
 	UseTags bool
 
-	// The url to push data to. Required.
+	// The url to push data to, as host:port. Metrics are pushed via TCP,
+	// opening a new connection for each push. Required unless Sink is set.
 	URL string
 
+	// The Sink to push data to instead of URL. If set, URL is ignored.
+	// Defaults to nil.
+	Sink Sink
+
 	// The prefix for the pushed Graphite metrics. Defaults to empty string.
 	Prefix string
 
 	// The interval to use for pushing data to Graphite. Defaults to 15 seconds.
 	Interval time.Duration
 
-	// The timeout for pushing metrics to Graphite. Defaults to 15 seconds.
+	// The timeout for pushing metrics to Graphite, passed to the Sink as
+	// the deadline of the context. Defaults to 15 seconds.
 	Timeout time.Duration
 
 	// The Gatherer to use for metrics. Defaults to prometheus.DefaultGatherer.
@@ -84,7 +90,7 @@ type Config struct {
 // Bridge pushes metrics to the configured Graphite server.
 type Bridge struct {
 	useTags  bool
-	url      string
+	sink     Sink
 	prefix   string
 	interval time.Duration
 	timeout  time.Duration
@@ -108,10 +114,9 @@ func NewBridge(c *Config) (*Bridge, error) {
 
 	b.useTags = c.UseTags
 
-	if c.URL == "" {
-		return nil, errors.New("missing URL")
+	if c.Sink == nil && c.URL == "" {
+		return nil, errors.New("missing URL or Sink")
 	}
-	b.url = c.URL
 
 	if c.Gatherer == nil {
 		b.g = prometheus.DefaultGatherer
@@ -140,11 +145,22 @@ func NewBridge(c *Config) (*Bridge, error) {
 		b.timeout = c.Timeout
 	}
 
+	if c.Sink != nil {
+		b.sink = c.Sink
+	} else {
+		b.sink = NewTCPSink(c.URL, 0)
+	}
+
 	b.errorHandling = c.ErrorHandling
 
 	return b, nil
 }
 
+// Close closes the Sink of the Bridge. The Bridge must not be used afterwards.
+func (b *Bridge) Close() error {
+	return b.sink.Close()
+}
+
 // Run starts the event loop that pushes Prometheus metrics to Graphite at the
 // configured interval.
 func (b *Bridge) Run(ctx context.Context) {
@@ -162,7 +178,7 @@ func (b *Bridge) Run(ctx context.Context) {
 	}
 }
 
-// Push pushes Prometheus metrics to the configured Graphite server.
+// Push pushes Prometheus metrics to the configured Graphite server or Sink.
 func (b *Bridge) Push() error {
 	mfs, err := b.g.Gather()
 	if err != nil || len(mfs) == 0 {
@@ -178,13 +194,22 @@ func (b *Bridge) Push() error {
 		}
 	}
 
-	conn, err := net.DialTimeout("tcp", b.url, b.timeout)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := writeMetrics(&buf, mfs, b.useTags, b.prefix, model.Now()); err != nil {
 		return err
 	}
-	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	return b.sink.WriteBatch(ctx, buf.Bytes())
+}
 
-	return writeMetrics(conn, mfs, b.useTags, b.prefix, model.Now())
+// WriteMetrics writes all samples of the provided metric families to w in the
+// Graphite plaintext protocol, one line per sample, just like a Bridge
+// configured with the same useTags and prefix would push them. Samples without
+// a timestamp get the provided one. It allows to reuse the formatting of the
+// Bridge with custom transports.
+func WriteMetrics(w io.Writer, mfs []*dto.MetricFamily, useTags bool, prefix string, now time.Time) error {
+	return writeMetrics(w, mfs, useTags, prefix, model.TimeFromUnixNano(now.UnixNano()))
 }
 
 func writeMetrics(w io.Writer, mfs []*dto.MetricFamily, useTags bool, prefix string, now model.Time) error {
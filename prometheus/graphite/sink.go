@@ -0,0 +1,165 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefUDPPacketSize is the default maximum size of the UDP packets sent by a
+// Sink created with NewUDPSink. It avoids fragmentation on networks with the
+// common MTU of 1500 bytes.
+const DefUDPPacketSize = 1432
+
+// Sink receives the metrics pushed by a Bridge, formatted in the Graphite
+// plaintext protocol. Implementations can send them to a Graphite server (see
+// NewTCPSink and NewUDPSink), to a file (see NewFileSink), or anywhere else,
+// e.g. to a message queue or to an in-memory buffer in tests (see
+// NewWriterSink).
+type Sink interface {
+	// WriteBatch is called once per push with all lines of the push. Each
+	// line is terminated by '\n'. The batch must not be retained after
+	// WriteBatch returns. The context carries the timeout configured for
+	// the Bridge.
+	WriteBatch(ctx context.Context, batch []byte) error
+	// Close releases any resources held by the Sink. It is called by
+	// Bridge.Close.
+	Close() error
+}
+
+// NewTCPSink returns a Sink that opens a new TCP connection to the provided
+// address (host:port) for each batch, which is how a Bridge configured with a
+// URL has always behaved. The timeout applies to establishing the connection
+// and to writing the batch, in addition to the deadline of the context passed
+// to WriteBatch, if any. If timeout is zero, no timeout applies.
+func NewTCPSink(address string, timeout time.Duration) Sink {
+	return &netSink{network: "tcp", address: address, timeout: timeout}
+}
+
+// NewUDPSink returns a Sink that sends each batch as UDP packets to the
+// provided address (host:port). Packets are split at line boundaries and are
+// at most maxPacketSize bytes large, unless a single line is larger. If
+// maxPacketSize is zero or negative, DefUDPPacketSize is used. Note that UDP
+// provides no delivery guarantees, so metrics may be lost silently.
+func NewUDPSink(address string, maxPacketSize int) Sink {
+	if maxPacketSize <= 0 {
+		maxPacketSize = DefUDPPacketSize
+	}
+	return &netSink{network: "udp", address: address, maxPacketSize: maxPacketSize}
+}
+
+type netSink struct {
+	network, address string
+	timeout          time.Duration
+	maxPacketSize    int // Only used for UDP.
+}
+
+// WriteBatch implements Sink.
+func (s *netSink) WriteBatch(ctx context.Context, batch []byte) error {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	if s.maxPacketSize == 0 {
+		_, err = conn.Write(batch)
+		return err
+	}
+	for len(batch) > 0 {
+		n := packetLen(batch, s.maxPacketSize)
+		if _, err := conn.Write(batch[:n]); err != nil {
+			return err
+		}
+		batch = batch[n:]
+	}
+	return nil
+}
+
+// Close implements Sink. It is a no-op as connections are not kept open.
+func (s *netSink) Close() error {
+	return nil
+}
+
+// packetLen returns the length of the longest prefix of batch consisting of
+// complete lines that is at most maxSize bytes long, or the length of the
+// first line if it is longer than maxSize.
+func packetLen(batch []byte, maxSize int) int {
+	if len(batch) <= maxSize {
+		return len(batch)
+	}
+	if i := bytes.LastIndexByte(batch[:maxSize], '\n'); i >= 0 {
+		return i + 1
+	}
+	if i := bytes.IndexByte(batch, '\n'); i >= 0 {
+		return i + 1
+	}
+	return len(batch)
+}
+
+// NewWriterSink returns a Sink that writes each batch to w. Calls of w.Write
+// are serialized. Closing the Sink does not close w.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+// NewFileSink returns a Sink that appends each batch to the file with the
+// provided name, which is created if it does not exist. Closing the Sink closes
+// the file.
+func NewFileSink(name string) (Sink, error) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &writerSink{w: f, closer: f}, nil
+}
+
+type writerSink struct {
+	mtx    sync.Mutex
+	w      io.Writer
+	closer io.Closer // Nil if w must not be closed.
+}
+
+// WriteBatch implements Sink.
+func (s *writerSink) WriteBatch(_ context.Context, batch []byte) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	_, err := s.w.Write(batch)
+	return err
+}
+
+// Close implements Sink.
+func (s *writerSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
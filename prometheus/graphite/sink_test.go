@@ -0,0 +1,173 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestBridgeWithWriterSink(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cnt := prometheus.NewCounter(prometheus.CounterOpts{Name: "name", Help: "docstring"})
+	cnt.Add(3)
+	reg.MustRegister(cnt)
+
+	var buf bytes.Buffer
+	b, err := NewBridge(&Config{
+		Sink:     NewWriterSink(&buf),
+		Gatherer: reg,
+		Prefix:   "prefix",
+	})
+	if err != nil {
+		t.Fatalf("error creating bridge: %v", err)
+	}
+	defer b.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := b.Push(); err != nil {
+			t.Fatalf("error pushing: %v", err)
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got %q", lines)
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "prefix.name 3 ") {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+
+	if _, err := NewBridge(&Config{}); err == nil {
+		t.Error("expected error creating bridge without URL and Sink")
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "name", Help: "docstring"}, []string{"label"})
+	g.WithLabelValues("a b").Set(1.5)
+	reg.MustRegister(g)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for useTags, want := range map[bool]string{
+		false: "prefix.name.label.a.b 1.5 1700000000\n",
+		true:  "prefix.name;label=a b 1.5 1700000000\n",
+	} {
+		var buf bytes.Buffer
+		if err := WriteMetrics(&buf, mfs, useTags, "prefix", time.Unix(1700000000, 0)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("useTags=%t: want %q, got %q", useTags, want, got)
+		}
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "graphite.txt")
+	s, err := NewFileSink(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, batch := range []string{"a 1 1\n", "b 2 2\n"} {
+		if err := s.WriteBatch(context.Background(), []byte(batch)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a 1 1\nb 2 2\n"; string(got) != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestUDPSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s := NewUDPSink(conn.LocalAddr().String(), 12)
+	defer s.Close()
+	batch := "a 1 1\nb 2 2\nthis_is_long 3 3\nc 4 4\n"
+	if err := s.WriteBatch(context.Background(), []byte(batch)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"a 1 1\nb 2 2\n", "this_is_long 3 3\n", "c 4 4\n"}
+	buf := make([]byte, 100)
+	for _, w := range want {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); got != w {
+			t.Errorf("want packet %q, got %q", w, got)
+		}
+	}
+}
+
+func TestTCPSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		var b bytes.Buffer
+		b.ReadFrom(conn)
+		conn.Close()
+		received <- b.String()
+	}()
+
+	s := NewTCPSink(ln.Addr().String(), time.Second)
+	defer s.Close()
+	if err := s.WriteBatch(context.Background(), []byte("a 1 1\n")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-received:
+		if got != "a 1 1\n" {
+			t.Errorf("want %q, got %q", "a 1 1\n", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no batch received")
+	}
+}
@@ -17,8 +17,10 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand/v2"
 	"runtime"
 	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -390,6 +392,11 @@ type HistogramOpts struct {
 	// https://prometheus.io/docs/instrumenting/writing_exporters/#target-labels-not-static-scraped-labels
 	ConstLabels Labels
 
+	// ConstLabelSet is an alternative to ConstLabels for callers holding the
+	// constant labels as LabelSet. Both are merged, with the value in
+	// ConstLabelSet taking precedence if a label name occurs in both.
+	ConstLabelSet LabelSet
+
 	// Buckets defines the buckets into which observations are counted. Each
 	// element in the slice is the upper inclusive bound of a bucket. The
 	// values must be sorted in strictly increasing order. There is no need
@@ -495,6 +502,29 @@ type HistogramOpts struct {
 	// 5m is used. To always delete the oldest exemplar, set it to a negative value.
 	NativeHistogramExemplarTTL time.Duration
 
+	// The next three fields enable sampling for histograms on code paths so
+	// hot that even a single observation is a measurable overhead. If
+	// SampleEvery is set to N > 1, only every Nth observation is recorded.
+	// If SampleProbability is set to a value p with 0 < p < 1, each
+	// observation is recorded with probability p. Setting both fields
+	// causes a panic upon creation of the histogram, as does a
+	// SampleProbability outside of the interval [0, 1].
+	//
+	// The count, the sum, and all buckets (classic and native) of a sampled
+	// histogram are estimates, i.e. the recorded values multiplied by N or
+	// 1/p, respectively. Observations that are not recorded do not receive
+	// exemplars. To make consumers aware of the estimation, the sampling
+	// rate is appended to the help string, e.g. "(sampled at rate 0.01)".
+	//
+	// If SampleExactSum is true, the values of observations that are not
+	// recorded are still added to the sum, so that the sum is exact rather
+	// than estimated. This costs an atomic compare-and-swap per skipped
+	// observation but still avoids the bucket lookup and the bucket and
+	// count updates.
+	SampleEvery       uint64
+	SampleProbability float64
+	SampleExactSum    bool
+
 	// now is for testing purposes, by default it's time.Now.
 	now func() time.Time
 
@@ -502,6 +532,35 @@ type HistogramOpts struct {
 	afterFunc func(time.Duration, func()) *time.Timer
 }
 
+// sampleScale returns the factor by which recorded counts have to be multiplied
+// to estimate the actual counts, or 0 if sampling is disabled. It panics if the
+// sampling configuration is invalid.
+func (o HistogramOpts) sampleScale() float64 {
+	if o.SampleEvery > 0 && o.SampleProbability != 0 {
+		panic(errors.New("histogram sampling: SampleEvery and SampleProbability are mutually exclusive"))
+	}
+	if o.SampleProbability < 0 || o.SampleProbability > 1 || math.IsNaN(o.SampleProbability) {
+		panic(fmt.Errorf("histogram sampling: SampleProbability must be in the interval [0, 1], got %v", o.SampleProbability))
+	}
+	switch {
+	case o.SampleEvery > 1:
+		return float64(o.SampleEvery)
+	case o.SampleProbability > 0 && o.SampleProbability < 1:
+		return 1 / o.SampleProbability
+	}
+	return 0
+}
+
+// sampledHelp returns the help string, with the sampling rate appended if
+// sampling is enabled.
+func (o HistogramOpts) sampledHelp() string {
+	scale := o.sampleScale()
+	if scale == 0 {
+		return o.Help
+	}
+	return fmt.Sprintf("%s (sampled at rate %s)", o.Help, strconv.FormatFloat(1/scale, 'g', -1, 64))
+}
+
 // HistogramVecOpts bundles the options to create a HistogramVec metric.
 // It is mandatory to set HistogramOpts, see there for mandatory fields. VariableLabels
 // is optional and can safely be left to its default value.
@@ -517,16 +576,16 @@ type HistogramVecOpts struct {
 // NewHistogram creates a new Histogram based on the provided HistogramOpts. It
 // panics if the buckets in HistogramOpts are not in strictly increasing order.
 //
-// The returned implementation also implements ExemplarObserver. It is safe to
-// perform the corresponding type assertion. Exemplars are tracked separately
-// for each bucket.
+// The returned implementation also implements ExemplarObserver and
+// LabelSetExemplarObserver. It is safe to perform the corresponding type
+// assertions. Exemplars are tracked separately for each bucket.
 func NewHistogram(opts HistogramOpts) Histogram {
 	return newHistogram(
 		NewDesc(
 			BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
-			opts.Help,
+			opts.sampledHelp(),
 			nil,
-			opts.ConstLabels,
+			opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 		),
 		opts,
 	)
@@ -565,6 +624,15 @@ func newHistogram(desc *Desc, opts HistogramOpts, labelValues ...string) Histogr
 		lastResetTime:                   opts.now(),
 		now:                             opts.now,
 		afterFunc:                       opts.afterFunc,
+		sampleScale:                     opts.sampleScale(),
+	}
+	if h.sampleScale != 0 {
+		if opts.SampleEvery > 1 {
+			h.sampleEvery = opts.SampleEvery
+		} else {
+			h.sampleProbability = opts.SampleProbability
+		}
+		h.sampleExactSum = opts.SampleExactSum
 	}
 	if len(h.upperBounds) == 0 && opts.NativeHistogramBucketFactor <= 1 {
 		h.upperBounds = DefBuckets
@@ -651,18 +719,19 @@ type histogramCounts struct {
 
 // observe manages the parts of observe that only affects
 // histogramCounts. doSparse is true if sparse buckets should be done,
-// too.
-func (hc *histogramCounts) observe(v float64, bucket int, doSparse bool) {
+// too. It returns whether a new sparse bucket has been created.
+func (hc *histogramCounts) observe(v float64, bucket int, doSparse bool) bool {
+	var bucketCreated bool
 	if bucket < len(hc.buckets) {
 		atomic.AddUint64(&hc.buckets[bucket], 1)
 	}
 	atomicAddFloat(&hc.sumBits, v)
 	if doSparse && !math.IsNaN(v) {
 		var (
-			key                  int
-			schema               = atomic.LoadInt32(&hc.nativeHistogramSchema)
-			zeroThreshold        = math.Float64frombits(atomic.LoadUint64(&hc.nativeHistogramZeroThresholdBits))
-			bucketCreated, isInf bool
+			key           int
+			schema        = atomic.LoadInt32(&hc.nativeHistogramSchema)
+			zeroThreshold = math.Float64frombits(atomic.LoadUint64(&hc.nativeHistogramZeroThresholdBits))
+			isInf         bool
 		)
 		if math.IsInf(v, 0) {
 			// Pretend v is MaxFloat64 but later increment key by one.
@@ -703,6 +772,7 @@ func (hc *histogramCounts) observe(v float64, bucket int, doSparse bool) {
 	// Increment count last as we take it as a signal that the observation
 	// is complete.
 	atomic.AddUint64(&hc.count, 1)
+	return bucketCreated
 }
 
 type histogram struct {
@@ -723,6 +793,12 @@ type histogram struct {
 	// Fields with atomic access first! See alignment constraint:
 	// http://golang.org/pkg/sync/atomic/#pkg-note-BUG
 	countAndHotIdx uint64
+	// sampleCounter counts all Observe calls if SampleEvery is used.
+	sampleCounter uint64
+	// skippedSumBits contains the bits of the float64 representing the sum
+	// of all observations not recorded because of sampling. It is only used
+	// if sampleExactSum is true.
+	skippedSumBits uint64
 
 	selfCollector
 	desc *Desc
@@ -752,6 +828,16 @@ type histogram struct {
 	resetScheduled  bool
 	nativeExemplars nativeExemplars
 
+	trackedMemory
+
+	// sampleScale is the factor to scale recorded counts with, or 0 if
+	// sampling is disabled. Only one of sampleEvery and sampleProbability
+	// is set if sampling is enabled.
+	sampleScale       float64
+	sampleEvery       uint64
+	sampleProbability float64
+	sampleExactSum    bool
+
 	// now is for testing purposes, by default it's time.Now.
 	now func() time.Time
 
@@ -764,6 +850,9 @@ func (h *histogram) Desc() *Desc {
 }
 
 func (h *histogram) Observe(v float64) {
+	if h.sampleScale != 0 && !h.sample(v) {
+		return
+	}
 	h.observe(v, h.findBucket(v))
 }
 
@@ -771,11 +860,29 @@ func (h *histogram) Observe(v float64) {
 // for a native histogram with configured exemplars. For this case,
 // the implementation isn't lock-free and might suffer from lock contention.
 func (h *histogram) ObserveWithExemplar(v float64, e Labels) {
+	if h.sampleScale != 0 && !h.sample(v) {
+		return
+	}
 	i := h.findBucket(v)
 	h.observe(v, i)
 	h.updateExemplar(v, i, e)
 }
 
+// ObserveWithExemplarLabelSet is subject to the same caveats as
+// ObserveWithExemplar.
+func (h *histogram) ObserveWithExemplarLabelSet(v float64, ls LabelSet) {
+	if h.sampleScale != 0 && !h.sample(v) {
+		return
+	}
+	i := h.findBucket(v)
+	h.observe(v, i)
+	e, err := newExemplarFromLabelSet(v, h.now(), ls)
+	if err != nil {
+		panic(err)
+	}
+	h.storeExemplar(v, i, e)
+}
+
 func (h *histogram) Write(out *dto.Metric) error {
 	// For simplicity, we protect this whole method by a mutex. It is not in
 	// the hot path, i.e. Observe is called much more often than Write. The
@@ -797,10 +904,18 @@ func (h *histogram) Write(out *dto.Metric) error {
 
 	waitForCooldown(count, coldCounts)
 
+	sum := math.Float64frombits(atomic.LoadUint64(&coldCounts.sumBits))
+	if h.sampleScale != 0 {
+		if h.sampleExactSum {
+			sum += math.Float64frombits(atomic.LoadUint64(&h.skippedSumBits))
+		} else {
+			sum *= h.sampleScale
+		}
+	}
 	his := &dto.Histogram{
 		Bucket:           make([]*dto.Bucket, len(h.upperBounds)),
-		SampleCount:      proto.Uint64(count),
-		SampleSum:        proto.Float64(math.Float64frombits(atomic.LoadUint64(&coldCounts.sumBits))),
+		SampleCount:      proto.Uint64(h.scaleCount(count)),
+		SampleSum:        proto.Float64(sum),
 		CreatedTimestamp: timestamppb.New(h.lastResetTime),
 	}
 	out.Histogram = his
@@ -810,7 +925,7 @@ func (h *histogram) Write(out *dto.Metric) error {
 	for i, upperBound := range h.upperBounds {
 		cumCount += atomic.LoadUint64(&coldCounts.buckets[i])
 		his.Bucket[i] = &dto.Bucket{
-			CumulativeCount: proto.Uint64(cumCount),
+			CumulativeCount: proto.Uint64(h.scaleCount(cumCount)),
 			UpperBound:      proto.Float64(upperBound),
 		}
 		if e := h.exemplars[i].Load(); e != nil {
@@ -820,7 +935,7 @@ func (h *histogram) Write(out *dto.Metric) error {
 	// If there is an exemplar for the +Inf bucket, we have to add that bucket explicitly.
 	if e := h.exemplars[len(h.upperBounds)].Load(); e != nil {
 		b := &dto.Bucket{
-			CumulativeCount: proto.Uint64(count),
+			CumulativeCount: proto.Uint64(h.scaleCount(count)),
 			UpperBound:      proto.Float64(math.Inf(1)),
 			Exemplar:        e.(*dto.Exemplar),
 		}
@@ -832,13 +947,30 @@ func (h *histogram) Write(out *dto.Metric) error {
 		zeroBucket := atomic.LoadUint64(&coldCounts.nativeHistogramZeroBucket)
 
 		defer func() {
-			coldCounts.nativeHistogramBucketsPositive.Range(addAndReset(&hotCounts.nativeHistogramBucketsPositive, &hotCounts.nativeHistogramBucketsNumber))
-			coldCounts.nativeHistogramBucketsNegative.Range(addAndReset(&hotCounts.nativeHistogramBucketsNegative, &hotCounts.nativeHistogramBucketsNumber))
+			coldCounts.nativeHistogramBucketsPositive.Range(h.addAndReset(hotCounts, &hotCounts.nativeHistogramBucketsPositive))
+			coldCounts.nativeHistogramBucketsNegative.Range(h.addAndReset(hotCounts, &hotCounts.nativeHistogramBucketsNegative))
 		}()
 
-		his.ZeroCount = proto.Uint64(zeroBucket)
+		his.ZeroCount = proto.Uint64(h.scaleCount(zeroBucket))
 		his.NegativeSpan, his.NegativeDelta = makeBuckets(&coldCounts.nativeHistogramBucketsNegative)
 		his.PositiveSpan, his.PositiveDelta = makeBuckets(&coldCounts.nativeHistogramBucketsPositive)
+		if h.sampleScale != 0 {
+			// The buckets are scaled and rounded individually, so the
+			// count has to be derived from them to stay consistent.
+			// Observations in no bucket (NaN) are scaled separately.
+			recordedNeg, scaledNeg := scaleDeltas(his.NegativeDelta, h.sampleScale)
+			recordedPos, scaledPos := scaleDeltas(his.PositiveDelta, h.sampleScale)
+			scaledCount := his.GetZeroCount() + scaledNeg + scaledPos
+			if recorded := zeroBucket + recordedNeg + recordedPos; recorded < count {
+				scaledCount += h.scaleCount(count - recorded)
+			}
+			his.SampleCount = proto.Uint64(scaledCount)
+			for _, b := range his.Bucket {
+				if b.GetCumulativeCount() > scaledCount || math.IsInf(b.GetUpperBound(), 1) {
+					b.CumulativeCount = proto.Uint64(scaledCount)
+				}
+			}
+		}
 
 		// Add a no-op span to a histogram without observations and with
 		// a zero threshold of zero. Otherwise, a native histogram would
@@ -861,6 +993,31 @@ func (h *histogram) Write(out *dto.Metric) error {
 	return nil
 }
 
+// sample decides if the observation of v is recorded. If it is not recorded, v
+// is added to the skipped sum if exact sums are requested.
+func (h *histogram) sample(v float64) bool {
+	var sampled bool
+	if h.sampleEvery > 0 {
+		// The first observation is always recorded.
+		sampled = (atomic.AddUint64(&h.sampleCounter, 1)-1)%h.sampleEvery == 0
+	} else {
+		sampled = rand.Float64() < h.sampleProbability
+	}
+	if !sampled && h.sampleExactSum {
+		atomicAddFloat(&h.skippedSumBits, v)
+	}
+	return sampled
+}
+
+// scaleCount returns the estimated count for the recorded count c. It is c
+// itself if sampling is disabled.
+func (h *histogram) scaleCount(c uint64) uint64 {
+	if h.sampleScale == 0 {
+		return c
+	}
+	return uint64(math.Round(float64(c) * h.sampleScale))
+}
+
 // findBucket returns the index of the bucket for the provided value, or
 // len(h.upperBounds) for the +Inf bucket.
 func (h *histogram) findBucket(v float64) int {
@@ -905,7 +1062,9 @@ func (h *histogram) observe(v float64, bucket int) {
 	// back, which we can use to find the currently-hot counts.
 	n := atomic.AddUint64(&h.countAndHotIdx, 1)
 	hotCounts := h.counts[n>>63]
-	hotCounts.observe(v, bucket, doSparse)
+	if hotCounts.observe(v, bucket, doSparse) {
+		h.trackNativeBuckets(1)
+	}
 	if doSparse {
 		h.limitBuckets(hotCounts, v, bucket)
 	}
@@ -980,6 +1139,7 @@ func (h *histogram) maybeReset(
 	waitForCooldown(count, hot)
 	// Finally, reset the formerly hot counts, too.
 	h.resetCounts(hot)
+	atomic.StoreUint64(&h.skippedSumBits, 0)
 	h.lastResetTime = h.now()
 	return true
 }
@@ -1003,6 +1163,7 @@ func (h *histogram) reset() {
 	waitForCooldown(count, hot)
 	// Finally, reset the formerly hot counts, too.
 	h.resetCounts(hot)
+	atomic.StoreUint64(&h.skippedSumBits, 0)
 	h.lastResetTime = h.now()
 	h.resetScheduled = false
 }
@@ -1035,10 +1196,10 @@ func (h *histogram) maybeWidenZeroBucket(hot, cold *histogramCounts) bool {
 	atomic.StoreUint64(&cold.nativeHistogramZeroThresholdBits, math.Float64bits(newZeroThreshold))
 	// Remove applicable buckets.
 	if _, loaded := cold.nativeHistogramBucketsNegative.LoadAndDelete(smallestKey); loaded {
-		atomicDecUint32(&cold.nativeHistogramBucketsNumber)
+		h.addNativeBucketsNumber(cold, -1)
 	}
 	if _, loaded := cold.nativeHistogramBucketsPositive.LoadAndDelete(smallestKey); loaded {
-		atomicDecUint32(&cold.nativeHistogramBucketsNumber)
+		h.addNativeBucketsNumber(cold, -1)
 	}
 	// Make cold counts the new hot counts.
 	n := atomic.AddUint64(&h.countAndHotIdx, 1<<63)
@@ -1062,11 +1223,11 @@ func (h *histogram) maybeWidenZeroBucket(hot, cold *histogramCounts) bool {
 				atomic.AddUint64(&hot.nativeHistogramZeroBucket, uint64(atomic.LoadInt64(bucket)))
 				// ...and delete from cold counts.
 				coldBuckets.Delete(key)
-				atomicDecUint32(&cold.nativeHistogramBucketsNumber)
+				h.addNativeBucketsNumber(cold, -1)
 			} else {
 				// Add to corresponding hot bucket...
 				if addToBucket(hotBuckets, key, atomic.LoadInt64(bucket)) {
-					atomic.AddUint32(&hot.nativeHistogramBucketsNumber, 1)
+					h.addNativeBucketsNumber(hot, 1)
 				}
 				// ...and reset cold bucket.
 				atomic.StoreInt64(bucket, 0)
@@ -1092,7 +1253,7 @@ func (h *histogram) doubleBucketWidth(hot, cold *histogramCounts) {
 	coldSchema--
 	atomic.StoreInt32(&cold.nativeHistogramSchema, coldSchema)
 	// Play it simple and just delete all cold buckets.
-	atomic.StoreUint32(&cold.nativeHistogramBucketsNumber, 0)
+	h.clearNativeBucketsNumber(cold)
 	deleteSyncMap(&cold.nativeHistogramBucketsNegative)
 	deleteSyncMap(&cold.nativeHistogramBucketsPositive)
 	// Make coldCounts the new hot counts.
@@ -1118,7 +1279,7 @@ func (h *histogram) doubleBucketWidth(hot, cold *histogramCounts) {
 			key /= 2
 			// Add to corresponding hot bucket.
 			if addToBucket(hotBuckets, key, atomic.LoadInt64(bucket)) {
-				atomic.AddUint32(&hot.nativeHistogramBucketsNumber, 1)
+				h.addNativeBucketsNumber(hot, 1)
 			}
 			return true
 		}
@@ -1127,7 +1288,7 @@ func (h *histogram) doubleBucketWidth(hot, cold *histogramCounts) {
 	cold.nativeHistogramBucketsPositive.Range(merge(&hot.nativeHistogramBucketsPositive))
 	cold.nativeHistogramBucketsNegative.Range(merge(&hot.nativeHistogramBucketsNegative))
 	// Play it simple again and just delete all cold buckets.
-	atomic.StoreUint32(&cold.nativeHistogramBucketsNumber, 0)
+	h.clearNativeBucketsNumber(cold)
 	deleteSyncMap(&cold.nativeHistogramBucketsNegative)
 	deleteSyncMap(&cold.nativeHistogramBucketsPositive)
 }
@@ -1138,7 +1299,7 @@ func (h *histogram) resetCounts(counts *histogramCounts) {
 	atomic.StoreUint64(&counts.nativeHistogramZeroBucket, 0)
 	atomic.StoreUint64(&counts.nativeHistogramZeroThresholdBits, math.Float64bits(h.nativeHistogramZeroThreshold))
 	atomic.StoreInt32(&counts.nativeHistogramSchema, h.nativeHistogramSchema)
-	atomic.StoreUint32(&counts.nativeHistogramBucketsNumber, 0)
+	h.clearNativeBucketsNumber(counts)
 	for i := range h.upperBounds {
 		atomic.StoreUint64(&counts.buckets[i], 0)
 	}
@@ -1158,10 +1319,19 @@ func (h *histogram) updateExemplar(v float64, bucket int, l Labels) {
 	if err != nil {
 		panic(err)
 	}
-	h.exemplars[bucket].Store(e)
+	h.storeExemplar(v, bucket, e)
+}
+
+// storeExemplar stores e as the exemplar of the bucket and, for native
+// histograms, adds it to the native exemplars.
+func (h *histogram) storeExemplar(v float64, bucket int, e *dto.Exemplar) {
+	old, _ := h.exemplars[bucket].Swap(e).(*dto.Exemplar)
+	h.trackExemplar(old, e)
 	doSparse := h.nativeHistogramSchema > math.MinInt32 && !math.IsNaN(v)
 	if doSparse {
-		h.nativeExemplars.addExemplar(e)
+		if replaced, ok := h.nativeExemplars.addExemplar(e); ok {
+			h.trackExemplar(replaced, e)
+		}
 	}
 }
 
@@ -1187,9 +1357,9 @@ func NewHistogramVec(opts HistogramOpts, labelNames []string) *HistogramVec {
 func (v2) NewHistogramVec(opts HistogramVecOpts) *HistogramVec {
 	desc := V2.NewDesc(
 		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
-		opts.Help,
+		opts.sampledHelp(),
 		opts.VariableLabels,
-		opts.ConstLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 	)
 	return &HistogramVec{
 		MetricVec: NewMetricVec(desc, func(lvs ...string) Metric {
@@ -1275,6 +1445,30 @@ func (v *HistogramVec) With(labels Labels) Observer {
 	return h
 }
 
+// GetMetricWithLabelSet works like GetMetricWith, but with the labels provided
+// as LabelSet, which avoids allocations on hot paths.
+func (v *HistogramVec) GetMetricWithLabelSet(ls LabelSet) (Observer, error) {
+	metric, err := v.MetricVec.GetMetricWithLabelSet(ls)
+	if metric != nil {
+		return metric.(Observer), err
+	}
+	return nil, err
+}
+
+// WithLabelSet works as GetMetricWithLabelSet, but panics where
+// GetMetricWithLabelSet would have returned an error. A LabelSet can be created
+// once and reused, like
+//
+//	notFound := prometheus.NewLabelSet("code", "404", "method", "GET")
+//	myVec.WithLabelSet(notFound).Observe(42.21)
+func (v *HistogramVec) WithLabelSet(ls LabelSet) Observer {
+	h, err := v.GetMetricWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
 // CurryWith returns a vector curried with the provided labels, i.e. the
 // returned vector has those labels pre-set for all labeled operations performed
 // on it. The cardinality of the curried vector is reduced accordingly. The
@@ -1306,6 +1500,26 @@ func (v *HistogramVec) MustCurryWith(labels Labels) ObserverVec {
 	return vec
 }
 
+// CurryWithLabelSet works like CurryWith, but with the labels provided as
+// LabelSet.
+func (v *HistogramVec) CurryWithLabelSet(ls LabelSet) (ObserverVec, error) {
+	vec, err := v.MetricVec.CurryWithLabelSet(ls)
+	if vec != nil {
+		return &HistogramVec{vec}, err
+	}
+	return nil, err
+}
+
+// MustCurryWithLabelSet works as CurryWithLabelSet but panics where
+// CurryWithLabelSet would have returned an error.
+func (v *HistogramVec) MustCurryWithLabelSet(ls LabelSet) ObserverVec {
+	vec, err := v.CurryWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return vec
+}
+
 type constHistogram struct {
 	desc       *Desc
 	count      uint64
@@ -1550,14 +1764,15 @@ func addToBucket(buckets *sync.Map, key int, increment int64) bool {
 
 // addAndReset returns a function to be used with sync.Map.Range of spare
 // buckets in coldCounts. It increments the buckets in the provided hotBuckets
-// according to the buckets ranged through. It then resets all buckets ranged
+// (belonging to the provided hot counts) according to the buckets ranged
+// through. It then resets all buckets ranged
 // through to 0 (but leaves them in place so that they don't need to get
 // recreated on the next scrape).
-func addAndReset(hotBuckets *sync.Map, bucketNumber *uint32) func(k, v interface{}) bool {
+func (h *histogram) addAndReset(hot *histogramCounts, hotBuckets *sync.Map) func(k, v interface{}) bool {
 	return func(k, v interface{}) bool {
 		bucket := v.(*int64)
 		if addToBucket(hotBuckets, k.(int), atomic.LoadInt64(bucket)) {
-			atomic.AddUint32(bucketNumber, 1)
+			h.addNativeBucketsNumber(hot, 1)
 		}
 		atomic.StoreInt64(bucket, 0)
 		return true
@@ -1656,10 +1871,36 @@ func atomicAddFloat(bits *uint64, v float64) {
 	}
 }
 
-// atomicDecUint32 atomically decrements the uint32 p points to.  See
-// https://pkg.go.dev/sync/atomic#AddUint32 to understand how this is done.
-func atomicDecUint32(p *uint32) {
-	atomic.AddUint32(p, ^uint32(0))
+// addNativeBucketsNumber adds delta to the number of sparse buckets in counts
+// and reports the change to the memory tracker.
+func (h *histogram) addNativeBucketsNumber(counts *histogramCounts, delta int) {
+	// A negative delta wraps around and is thereby subtracted, see
+	// https://pkg.go.dev/sync/atomic#AddUint32.
+	atomic.AddUint32(&counts.nativeHistogramBucketsNumber, uint32(delta))
+	h.trackNativeBuckets(delta)
+}
+
+// clearNativeBucketsNumber sets the number of sparse buckets in counts to zero
+// and reports the change to the memory tracker.
+func (h *histogram) clearNativeBucketsNumber(counts *histogramCounts) {
+	n := atomic.SwapUint32(&counts.nativeHistogramBucketsNumber, 0)
+	h.trackNativeBuckets(-int(n))
+}
+
+// scaleDeltas scales the bucket counts encoded by the provided deltas in place,
+// rounding each scaled count to the nearest integer. It returns the sum of the
+// bucket counts before and after scaling.
+func scaleDeltas(deltas []int64, scale float64) (recorded, scaled uint64) {
+	var count, scaledCount int64
+	for i, d := range deltas {
+		count += d
+		s := int64(math.Round(float64(count) * scale))
+		deltas[i] = s - scaledCount
+		scaledCount = s
+		recorded += uint64(count)
+		scaled += uint64(s)
+	}
+	return recorded, scaled
 }
 
 // addAndResetCounts adds certain fields (count, sum, conventional buckets, zero
@@ -1713,9 +1954,11 @@ func makeNativeExemplars(ttl time.Duration, maxCount int) nativeExemplars {
 	}
 }
 
-func (n *nativeExemplars) addExemplar(e *dto.Exemplar) {
+// addExemplar adds e to the exemplars. It returns the exemplar replaced by e,
+// or nil if e was added without replacing one, and whether e was added at all.
+func (n *nativeExemplars) addExemplar(e *dto.Exemplar) (*dto.Exemplar, bool) {
 	if !n.isEnabled() {
-		return
+		return nil, false
 	}
 
 	n.Lock()
@@ -1732,14 +1975,15 @@ func (n *nativeExemplars) addExemplar(e *dto.Exemplar) {
 			}
 		}
 		n.exemplars = append(n.exemplars[:nIdx], append([]*dto.Exemplar{e}, n.exemplars[nIdx:]...)...)
-		return
+		return nil, true
 	}
 
 	if len(n.exemplars) == 1 {
 		// When the number of exemplars is 1, then
 		// replace the existing exemplar with the new exemplar.
+		replaced := n.exemplars[0]
 		n.exemplars[0] = e
-		return
+		return replaced, true
 	}
 	// From this point on, the number of exemplars is greater than 1.
 
@@ -1852,6 +2096,7 @@ func (n *nativeExemplars) addExemplar(e *dto.Exemplar) {
 	}
 
 	// Adjust the slice according to rIdx and nIdx.
+	replaced := n.exemplars[rIdx]
 	switch {
 	case rIdx == nIdx:
 		n.exemplars[nIdx] = e
@@ -1860,6 +2105,7 @@ func (n *nativeExemplars) addExemplar(e *dto.Exemplar) {
 	case rIdx > nIdx:
 		n.exemplars = append(n.exemplars[:nIdx], append([]*dto.Exemplar{e}, append(n.exemplars[nIdx:rIdx], n.exemplars[rIdx+1:]...)...)...)
 	}
+	return replaced, true
 }
 
 type constNativeHistogram struct {
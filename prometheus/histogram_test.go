@@ -2085,3 +2085,154 @@ func TestConstNativeHistogram(t *testing.T) {
 		})
 	}
 }
+
+func TestHistogramSampleEvery(t *testing.T) {
+	h := NewHistogram(HistogramOpts{
+		Name:                        "test_histogram",
+		Help:                        "helpless",
+		Buckets:                     []float64{1, 10},
+		NativeHistogramBucketFactor: 2,
+		SampleEvery:                 10,
+	}).(*histogram)
+	if got, want := h.Desc().help, "helpless (sampled at rate 0.1)"; got != want {
+		t.Errorf("got help %q, want %q", got, want)
+	}
+
+	// Every 10th observation, starting with the first, is recorded, i.e.
+	// 2 for each of 5 and 20.
+	for i := 0; i < 20; i++ {
+		h.Observe(5)
+	}
+	for i := 0; i < 20; i++ {
+		h.Observe(20)
+	}
+
+	m := &dto.Metric{}
+	if err := h.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	his := m.GetHistogram()
+	if got, want := his.GetSampleCount(), uint64(40); got != want {
+		t.Errorf("got sample count %d, want %d", got, want)
+	}
+	if got, want := his.GetSampleSum(), 500.; got != want {
+		t.Errorf("got sample sum %v, want %v", got, want)
+	}
+	var cumCounts []uint64
+	for _, b := range his.GetBucket() {
+		cumCounts = append(cumCounts, b.GetCumulativeCount())
+	}
+	if want := []uint64{0, 20}; !reflect.DeepEqual(cumCounts, want) {
+		t.Errorf("got cumulative counts %v, want %v", cumCounts, want)
+	}
+	// 5 is in native bucket 3 (4, 8], 20 in bucket 5 (16, 32].
+	if got, want := his.GetPositiveDelta(), []int64{20, -20, 20}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got positive deltas %v, want %v", got, want)
+	}
+}
+
+func TestHistogramSampleExactSum(t *testing.T) {
+	h := NewHistogram(HistogramOpts{
+		Name:           "test_histogram",
+		Help:           "helpless",
+		SampleEvery:    3,
+		SampleExactSum: true,
+	})
+	for i := 1; i <= 4; i++ {
+		h.Observe(float64(i))
+	}
+
+	m := &dto.Metric{}
+	if err := h.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	// Observations 1 and 4 are recorded.
+	if got, want := m.GetHistogram().GetSampleCount(), uint64(6); got != want {
+		t.Errorf("got sample count %d, want %d", got, want)
+	}
+	if got, want := m.GetHistogram().GetSampleSum(), 10.; got != want {
+		t.Errorf("got sample sum %v, want %v", got, want)
+	}
+}
+
+func TestHistogramSampleProbability(t *testing.T) {
+	const n = 100000
+	h := NewHistogram(HistogramOpts{
+		Name:              "test_histogram",
+		Help:              "helpless",
+		SampleProbability: 0.5,
+	})
+	for i := 0; i < n; i++ {
+		h.Observe(1)
+	}
+
+	m := &dto.Metric{}
+	if err := h.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	// The standard deviation of the estimated count is ~316.
+	if got := m.GetHistogram().GetSampleCount(); got < n*0.97 || got > n*1.03 {
+		t.Errorf("got estimated sample count %d, want close to %d", got, n)
+	}
+	if got := m.GetHistogram().GetSampleCount(); float64(got) != m.GetHistogram().GetSampleSum() {
+		t.Errorf("got sample count %d and sum %v, want them to be equal", got, m.GetHistogram().GetSampleSum())
+	}
+}
+
+func TestHistogramSampleProbabilityConsistency(t *testing.T) {
+	h := NewHistogram(HistogramOpts{
+		Name:                        "test_histogram",
+		Help:                        "helpless",
+		Buckets:                     []float64{1, 10, 100},
+		NativeHistogramBucketFactor: 1.1,
+		SampleProbability:           0.3,
+	})
+	for i := 0; i < 10000; i++ {
+		h.Observe(float64(i%1000) - 100)
+	}
+	h.Observe(math.NaN())
+
+	m := &dto.Metric{}
+	if err := h.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	his := m.GetHistogram()
+	count := his.GetSampleCount()
+	bucketSum := his.GetZeroCount()
+	for _, deltas := range [][]int64{his.GetNegativeDelta(), his.GetPositiveDelta()} {
+		var c int64
+		for _, d := range deltas {
+			c += d
+			bucketSum += uint64(c)
+		}
+	}
+	// The NaN observation may or may not have been sampled.
+	if bucketSum != count && bucketSum+uint64(math.Round(1/0.3)) != count {
+		t.Errorf("got sample count %d, want it to match the sum of the native buckets %d", count, bucketSum)
+	}
+	var last uint64
+	for _, b := range his.GetBucket() {
+		if c := b.GetCumulativeCount(); c < last || c > count {
+			t.Errorf("got inconsistent cumulative count %d of bucket %v (previous %d, sample count %d)", c, b.GetUpperBound(), last, count)
+		}
+		last = b.GetCumulativeCount()
+	}
+}
+
+func TestHistogramSampleInvalidOpts(t *testing.T) {
+	for name, opts := range map[string]HistogramOpts{
+		"both":                 {SampleEvery: 10, SampleProbability: 0.1},
+		"negative probability": {SampleProbability: -0.1},
+		"probability above 1":  {SampleProbability: 1.5},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			opts.Name, opts.Help = "test_histogram", "helpless"
+			NewHistogram(opts)
+		})
+	}
+}
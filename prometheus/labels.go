@@ -16,6 +16,7 @@ package prometheus
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"unicode/utf8"
 
@@ -41,8 +42,25 @@ type LabelConstraint func(string) string
 type ConstrainedLabel struct {
 	Name       string
 	Constraint LabelConstraint
+	// KnownValues are the label values known in advance, e.g. the possible
+	// status codes of a request. If KnownValues are set for all variable
+	// labels of a metric vector, the vector creates the metrics for all
+	// combinations of the known values (after applying Constraint) upon
+	// construction, so that they are exported with their initial value
+	// before first use. This is useful for series that are otherwise only
+	// created once an error happens. The number of combinations must not
+	// exceed MaxPreInitializedMetrics. The vector still accepts label
+	// values that are not known in advance. A deleted metric is only
+	// re-created upon its next use or by a Reset of the vector, which
+	// re-creates all metrics for the known values.
+	KnownValues []string
 }
 
+// MaxPreInitializedMetrics is the maximum number of metrics a metric vector
+// creates upon construction from the KnownValues of its variable labels.
+// Constructing a vector with more combinations of known values panics.
+const MaxPreInitializedMetrics = 10000
+
 // ConstrainableLabels is an interface that allows creating of labels that can
 // be optionally constrained.
 //
@@ -74,6 +92,12 @@ func (cls ConstrainedLabels) compile() *compiledLabels {
 		if label.Constraint != nil {
 			compiled.labelConstraints[label.Name] = label.Constraint
 		}
+		if len(label.KnownValues) > 0 {
+			if compiled.knownValues == nil {
+				compiled.knownValues = make([][]string, len(cls))
+			}
+			compiled.knownValues[i] = label.KnownValues
+		}
 	}
 
 	return compiled
@@ -113,6 +137,9 @@ func (uls UnconstrainedLabels) labelNames() []string {
 type compiledLabels struct {
 	names            []string
 	labelConstraints map[string]LabelConstraint
+	// knownValues is nil if no label has known values. Otherwise, it has
+	// the same length as names.
+	knownValues [][]string
 }
 
 func (cls *compiledLabels) compile() *compiledLabels {
@@ -186,3 +213,127 @@ func validateLabelValues(vals []string, expectedNumberOfValues int) error {
 func checkLabelName(l string) bool {
 	return model.LabelName(l).IsValid() && !strings.HasPrefix(l, reservedLabelPrefix)
 }
+
+// MatchType is an enumeration of the ways a LabelMatcher can match a label
+// value.
+type MatchType int
+
+// Possible values for the MatchType enum.
+const (
+	// MatchEqual matches if the label value equals the given value.
+	MatchEqual MatchType = iota
+	// MatchNotEqual matches if the label value differs from the given value.
+	MatchNotEqual
+	// MatchRegexp matches if the label value matches the given regular
+	// expression. The expression is fully anchored.
+	MatchRegexp
+	// MatchNotRegexp matches if the label value does not match the given
+	// regular expression. The expression is fully anchored.
+	MatchNotRegexp
+	// MatchIn matches if the label value is one of the given values.
+	MatchIn
+	// MatchNotIn matches if the label value is none of the given values.
+	MatchNotIn
+)
+
+func (t MatchType) String() string {
+	switch t {
+	case MatchEqual:
+		return "="
+	case MatchNotEqual:
+		return "!="
+	case MatchRegexp:
+		return "=~"
+	case MatchNotRegexp:
+		return "!~"
+	case MatchIn:
+		return "in"
+	case MatchNotIn:
+		return "not in"
+	default:
+		return fmt.Sprintf("MatchType(%d)", int(t))
+	}
+}
+
+// LabelMatcher matches the value of the label with the given Name. Use
+// NewLabelMatcher to create instances. A label that is not present is treated
+// as having an empty value, following the semantics of PromQL label matchers.
+type LabelMatcher struct {
+	Type   MatchType
+	Name   string
+	Values []string
+
+	re  *regexp.Regexp
+	set map[string]struct{}
+}
+
+// NewLabelMatcher returns a LabelMatcher of the given type for the label with
+// the given name. MatchEqual, MatchNotEqual, MatchRegexp, and MatchNotRegexp
+// take exactly one value, while MatchIn and MatchNotIn take any number of
+// values. An error is returned if the number of values does not fit the type
+// or if a regular expression does not compile.
+func NewLabelMatcher(t MatchType, name string, values ...string) (*LabelMatcher, error) {
+	m := &LabelMatcher{Type: t, Name: name, Values: values}
+	switch t {
+	case MatchEqual, MatchNotEqual:
+		if len(values) != 1 {
+			return nil, fmt.Errorf("label matcher %q for label %q requires exactly one value, got %d", t, name, len(values))
+		}
+	case MatchRegexp, MatchNotRegexp:
+		if len(values) != 1 {
+			return nil, fmt.Errorf("label matcher %q for label %q requires exactly one value, got %d", t, name, len(values))
+		}
+		re, err := regexp.Compile("^(?:" + values[0] + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("label matcher %q for label %q: %w", t, name, err)
+		}
+		m.re = re
+	case MatchIn, MatchNotIn:
+		m.set = make(map[string]struct{}, len(values))
+		for _, v := range values {
+			m.set[v] = struct{}{}
+		}
+	default:
+		return nil, fmt.Errorf("unknown label match type %d", int(t))
+	}
+	return m, nil
+}
+
+// MustNewLabelMatcher works as NewLabelMatcher but panics where
+// NewLabelMatcher would have returned an error.
+func MustNewLabelMatcher(t MatchType, name string, values ...string) *LabelMatcher {
+	m, err := NewLabelMatcher(t, name, values...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Matches returns whether the provided label value matches.
+func (m *LabelMatcher) Matches(v string) bool {
+	switch m.Type {
+	case MatchEqual:
+		return v == m.Values[0]
+	case MatchNotEqual:
+		return v != m.Values[0]
+	case MatchRegexp:
+		return m.re.MatchString(v)
+	case MatchNotRegexp:
+		return !m.re.MatchString(v)
+	case MatchIn:
+		_, ok := m.set[v]
+		return ok
+	case MatchNotIn:
+		_, ok := m.set[v]
+		return !ok
+	default:
+		panic(fmt.Sprintf("unknown label match type %d", int(m.Type)))
+	}
+}
+
+func (m *LabelMatcher) String() string {
+	if m.Type == MatchIn || m.Type == MatchNotIn {
+		return fmt.Sprintf("%s %s %q", m.Name, m.Type, m.Values)
+	}
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Values[0])
+}
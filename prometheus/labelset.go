@@ -0,0 +1,271 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Label is a single label name and value.
+type Label struct {
+	Name, Value string
+}
+
+// LabelSet is an immutable set of labels, sorted by label name. It is a
+// performance-oriented alternative to Labels: Where Labels has to be allocated
+// as a map for each call of e.g. With or ObserveWithExemplar, a LabelSet can be
+// created once and reused, or assembled with a pooled LabelSetBuilder, without
+// any allocations. Methods accepting a LabelSet look up label names in the sorted
+// slice rather than by hashing.
+//
+// The zero value is an empty LabelSet.
+type LabelSet struct {
+	labels []Label
+}
+
+// NewLabelSet returns a LabelSet from alternating label names and values,
+// e.g. NewLabelSet("code", "200", "method", "GET"). It panics if the number of
+// arguments is odd or if a label name occurs more than once.
+func NewLabelSet(nameValues ...string) LabelSet {
+	if len(nameValues)%2 != 0 {
+		panic(fmt.Errorf("odd number of label names and values: %q", nameValues))
+	}
+	labels := make([]Label, 0, len(nameValues)/2)
+	for i := 0; i < len(nameValues); i += 2 {
+		labels = append(labels, Label{Name: nameValues[i], Value: nameValues[i+1]})
+	}
+	return makeLabelSet(labels)
+}
+
+// LabelSetFromLabels returns a LabelSet with the same labels as l.
+func LabelSetFromLabels(l Labels) LabelSet {
+	labels := make([]Label, 0, len(l))
+	for name, value := range l {
+		labels = append(labels, Label{Name: name, Value: value})
+	}
+	return makeLabelSet(labels)
+}
+
+func makeLabelSet(labels []Label) LabelSet {
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	for i := 1; i < len(labels); i++ {
+		if labels[i].Name == labels[i-1].Name {
+			panic(fmt.Errorf("duplicate label name %q", labels[i].Name))
+		}
+	}
+	return LabelSet{labels: labels}
+}
+
+// Len returns the number of labels in the LabelSet.
+func (ls LabelSet) Len() int {
+	return len(ls.labels)
+}
+
+// At returns the i-th label in order of label names. It panics if i is out of
+// range.
+func (ls LabelSet) At(i int) Label {
+	return ls.labels[i]
+}
+
+// Get returns the value of the label with the provided name and whether the
+// label exists.
+func (ls LabelSet) Get(name string) (string, bool) {
+	// Linear search is faster for the usual small label sets.
+	if len(ls.labels) <= 8 {
+		for _, l := range ls.labels {
+			if l.Name == name {
+				return l.Value, true
+			}
+		}
+		return "", false
+	}
+	i := sort.Search(len(ls.labels), func(i int) bool { return ls.labels[i].Name >= name })
+	if i < len(ls.labels) && ls.labels[i].Name == name {
+		return ls.labels[i].Value, true
+	}
+	return "", false
+}
+
+// Labels returns the labels in the LabelSet as a newly allocated Labels map.
+func (ls LabelSet) Labels() Labels {
+	l := make(Labels, len(ls.labels))
+	for _, label := range ls.labels {
+		l[label.Name] = label.Value
+	}
+	return l
+}
+
+// mergeInto returns a Labels map with the labels of l and ls, with the values
+// in ls taking precedence. l is not modified. If ls is empty, l is returned.
+func (ls LabelSet) mergeInto(l Labels) Labels {
+	if len(ls.labels) == 0 {
+		return l
+	}
+	merged := make(Labels, len(l)+len(ls.labels))
+	for name, value := range l {
+		merged[name] = value
+	}
+	for _, label := range ls.labels {
+		merged[label.Name] = label.Value
+	}
+	return merged
+}
+
+// Copy returns a LabelSet that does not share memory with ls. It is required
+// to retain a LabelSet returned by LabelSetBuilder.LabelSet beyond the next
+// modification of the builder.
+func (ls LabelSet) Copy() LabelSet {
+	return LabelSet{labels: append([]Label(nil), ls.labels...)}
+}
+
+// String returns the LabelSet in the format of the text exposition format,
+// e.g. {code="200",method="GET"}.
+func (ls LabelSet) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, l := range ls.labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", l.Name, l.Value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// LabelSetBuilder assembles a LabelSet. Its zero value is ready to use. Once
+// its buffer has grown to the required size, a LabelSetBuilder does not
+// allocate anymore, so reusing it, e.g. via GetLabelSetBuilder and
+// PutLabelSetBuilder, allows to create LabelSets on hot paths without any
+// allocations.
+type LabelSetBuilder struct {
+	labels []Label
+}
+
+var labelSetBuilderPool = sync.Pool{
+	New: func() interface{} { return &LabelSetBuilder{} },
+}
+
+// GetLabelSetBuilder returns an empty LabelSetBuilder from a pool. Return it
+// with PutLabelSetBuilder once the LabelSet created by it isn't used anymore.
+func GetLabelSetBuilder() *LabelSetBuilder {
+	return labelSetBuilderPool.Get().(*LabelSetBuilder)
+}
+
+// PutLabelSetBuilder resets b and returns it to the pool used by
+// GetLabelSetBuilder. Neither b nor any LabelSet created by it must be used
+// afterwards.
+func PutLabelSetBuilder(b *LabelSetBuilder) {
+	b.Reset()
+	labelSetBuilderPool.Put(b)
+}
+
+// Set sets the label with the provided name to the provided value, replacing
+// any previous value. It returns b to allow chaining.
+func (b *LabelSetBuilder) Set(name, value string) *LabelSetBuilder {
+	// Keep the labels sorted by inserting at the right position.
+	i := len(b.labels)
+	for i > 0 && b.labels[i-1].Name >= name {
+		i--
+	}
+	if i < len(b.labels) && b.labels[i].Name == name {
+		b.labels[i].Value = value
+		return b
+	}
+	b.labels = append(b.labels, Label{})
+	copy(b.labels[i+1:], b.labels[i:])
+	b.labels[i] = Label{Name: name, Value: value}
+	return b
+}
+
+// Del removes the label with the provided name, if present. It returns b to
+// allow chaining.
+func (b *LabelSetBuilder) Del(name string) *LabelSetBuilder {
+	for i, l := range b.labels {
+		if l.Name == name {
+			b.labels = append(b.labels[:i], b.labels[i+1:]...)
+			break
+		}
+	}
+	return b
+}
+
+// Reset removes all labels from b while keeping its buffer.
+func (b *LabelSetBuilder) Reset() {
+	b.labels = b.labels[:0]
+}
+
+// LabelSet returns the LabelSet assembled so far. It shares memory with b and
+// is therefore only valid until the next call of a modifying method of b. Use
+// LabelSet.Copy to retain it beyond that.
+func (b *LabelSetBuilder) LabelSet() LabelSet {
+	return LabelSet{labels: b.labels}
+}
+
+// LabelSetExemplarAdder is implemented by Counters that offer the option of
+// adding a value to the Counter together with an exemplar specified as
+// LabelSet. It works like ExemplarAdder, but avoids the allocation of a Labels
+// map.
+type LabelSetExemplarAdder interface {
+	AddWithExemplarLabelSet(value float64, exemplar LabelSet)
+}
+
+// LabelSetExemplarObserver is implemented by Observers that offer the option of
+// observing a value together with an exemplar specified as LabelSet. It works
+// like ExemplarObserver, but avoids the allocation of a Labels map.
+type LabelSetExemplarObserver interface {
+	ObserveWithExemplarLabelSet(value float64, exemplar LabelSet)
+}
+
+// newExemplarFromLabelSet works like newExemplar, but with the labels provided
+// as LabelSet. The label pairs are allocated in one go.
+func newExemplarFromLabelSet(value float64, ts time.Time, ls LabelSet) (*dto.Exemplar, error) {
+	e := &dto.Exemplar{}
+	e.Value = proto.Float64(value)
+	tsProto := timestamppb.New(ts)
+	if err := tsProto.CheckValid(); err != nil {
+		return nil, err
+	}
+	e.Timestamp = tsProto
+	labelPairs := make([]dto.LabelPair, len(ls.labels))
+	e.Label = make([]*dto.LabelPair, len(ls.labels))
+	var runes int
+	for i, l := range ls.labels {
+		if !checkLabelName(l.Name) {
+			return nil, fmt.Errorf("exemplar label name %q is invalid", l.Name)
+		}
+		runes += utf8.RuneCountInString(l.Name)
+		if !utf8.ValidString(l.Value) {
+			return nil, fmt.Errorf("exemplar label value %q is not valid UTF-8", l.Value)
+		}
+		runes += utf8.RuneCountInString(l.Value)
+		// Point to copies, as ls might share memory with a pooled builder.
+		name, value := l.Name, l.Value
+		labelPairs[i].Name, labelPairs[i].Value = &name, &value
+		e.Label[i] = &labelPairs[i]
+	}
+	if runes > ExemplarMaxRunes {
+		return nil, fmt.Errorf("exemplar labels have %d runes, exceeding the limit of %d", runes, ExemplarMaxRunes)
+	}
+	return e, nil
+}
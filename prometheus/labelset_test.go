@@ -0,0 +1,211 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestLabelSetBuilder(t *testing.T) {
+	b := GetLabelSetBuilder()
+	defer PutLabelSetBuilder(b)
+
+	b.Set("method", "GET").Set("code", "500").Set("path", "/").Set("code", "200").Del("path")
+	ls := b.LabelSet()
+	if got, want := ls.String(), `{code="200",method="GET"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got := NewLabelSet("method", "GET", "code", "200"); !reflect.DeepEqual(got, ls) {
+		t.Errorf("got %s from NewLabelSet, want %s", got, ls)
+	}
+	if got := LabelSetFromLabels(Labels{"method": "GET", "code": "200"}); !reflect.DeepEqual(got, ls) {
+		t.Errorf("got %s from LabelSetFromLabels, want %s", got, ls)
+	}
+	if v, ok := ls.Get("method"); !ok || v != "GET" {
+		t.Errorf("got %q (%v) for method, want GET", v, ok)
+	}
+	if _, ok := ls.Get("path"); ok {
+		t.Error("deleted label found")
+	}
+
+	retained := ls.Copy()
+	b.Reset()
+	b.Set("a", "b")
+	if got := retained.Labels(); !reflect.DeepEqual(got, Labels{"code": "200", "method": "GET"}) {
+		t.Errorf("copy changed after reuse of the builder: %v", got)
+	}
+}
+
+func TestNewLabelSetDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	NewLabelSet("a", "1", "a", "2")
+}
+
+func TestVecWithLabelSet(t *testing.T) {
+	vec := V2.NewCounterVec(CounterVecOpts{
+		CounterOpts: CounterOpts{Name: "test", Help: "helpless"},
+		VariableLabels: ConstrainedLabels{
+			{Name: "code"},
+			{Name: "method", Constraint: strings.ToUpper},
+		},
+	})
+	vec.WithLabelSet(NewLabelSet("code", "200", "method", "get")).Inc()
+	vec.With(Labels{"code": "200", "method": "GET"}).Inc()
+
+	m := &dto.Metric{}
+	if err := vec.WithLabelValues("200", "GET").Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetCounter().GetValue(); got != 2 {
+		t.Errorf("got %v, want 2", got)
+	}
+
+	curried := vec.MustCurryWith(Labels{"code": "200"})
+	if _, err := curried.GetMetricWithLabelSet(NewLabelSet("code", "200", "method", "GET")); err == nil {
+		t.Error("expected error for curried label")
+	}
+	if _, err := curried.GetMetricWithLabelSet(NewLabelSet("path", "/")); err == nil {
+		t.Error("expected error for unknown label")
+	}
+	if !curried.DeleteLabelSet(NewLabelSet("method", "get")) {
+		t.Error("metric not deleted")
+	}
+}
+
+func TestCurryAndConstLabelSet(t *testing.T) {
+	vec := V2.NewGaugeVec(GaugeVecOpts{
+		GaugeOpts: GaugeOpts{
+			Name:          "test",
+			Help:          "helpless",
+			ConstLabels:   Labels{"a": "1", "b": "1"},
+			ConstLabelSet: NewLabelSet("b", "2", "c", "3"),
+		},
+		VariableLabels: UnconstrainedLabels{"code", "method"},
+	})
+	curried := vec.MustCurryWithLabelSet(NewLabelSet("code", "200"))
+	curried.WithLabelValues("GET").Set(1)
+	if _, err := curried.CurryWithLabelSet(NewLabelSet("code", "404")); err == nil {
+		t.Error("expected error for already curried label")
+	}
+	if _, err := vec.CurryWithLabelSet(NewLabelSet("path", "/")); err == nil {
+		t.Error("expected error for unknown label")
+	}
+
+	reg := NewRegistry()
+	WrapRegistererWithLabelSet(NewLabelSet("d", "4"), reg).MustRegister(vec)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := LabelSet{}
+	for _, lp := range mfs[0].GetMetric()[0].GetLabel() {
+		got.labels = append(got.labels, Label{Name: lp.GetName(), Value: lp.GetValue()})
+	}
+	want := NewLabelSet("a", "1", "b", "2", "c", "3", "code", "200", "d", "4", "method", "GET")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got labels %s, want %s", got, want)
+	}
+}
+
+func TestExemplarLabelSet(t *testing.T) {
+	ls := NewLabelSet("trace_id", "abc", "span_id", "def")
+	want := []*dto.LabelPair{
+		{Name: strPtr("span_id"), Value: strPtr("def")},
+		{Name: strPtr("trace_id"), Value: strPtr("abc")},
+	}
+
+	c := NewCounter(CounterOpts{Name: "test", Help: "helpless"})
+	c.(LabelSetExemplarAdder).AddWithExemplarLabelSet(1, ls)
+	m := &dto.Metric{}
+	if err := c.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetCounter().GetExemplar().GetLabel(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got counter exemplar labels %v, want %v", got, want)
+	}
+
+	h := NewHistogram(HistogramOpts{Name: "test", Help: "helpless", Buckets: []float64{1}})
+	h.(LabelSetExemplarObserver).ObserveWithExemplarLabelSet(0.5, ls)
+	m = &dto.Metric{}
+	if err := h.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetHistogram().GetBucket()[0].GetExemplar().GetLabel(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got histogram exemplar labels %v, want %v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid label name")
+		}
+	}()
+	c.(LabelSetExemplarAdder).AddWithExemplarLabelSet(1, NewLabelSet("__reserved", "x"))
+}
+
+func strPtr(s string) *string { return &s }
+
+func BenchmarkVecWithLabels(b *testing.B) {
+	vec := NewCounterVec(CounterOpts{Name: "test", Help: "helpless"}, []string{"code", "method"})
+	code, method := "200", "GET"
+
+	b.Run("Labels", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			vec.With(Labels{"code": code, "method": method}).Inc()
+		}
+	})
+	b.Run("LabelSet", func(b *testing.B) {
+		ls := NewLabelSet("code", code, "method", method)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			vec.WithLabelSet(ls).Inc()
+		}
+	})
+	b.Run("LabelSetBuilder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lsb := GetLabelSetBuilder()
+			vec.WithLabelSet(lsb.Set("code", code).Set("method", method).LabelSet()).Inc()
+			PutLabelSetBuilder(lsb)
+		}
+	})
+}
+
+func BenchmarkObserveWithExemplar(b *testing.B) {
+	h := NewHistogram(HistogramOpts{Name: "test", Help: "helpless"})
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	b.Run("Labels", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			h.(ExemplarObserver).ObserveWithExemplar(0.1, Labels{"trace_id": traceID})
+		}
+	})
+	b.Run("LabelSet", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lsb := GetLabelSetBuilder()
+			h.(LabelSetExemplarObserver).ObserveWithExemplarLabelSet(0.1, lsb.Set("trace_id", traceID).LabelSet())
+			PutLabelSetBuilder(lsb)
+		}
+	})
+}
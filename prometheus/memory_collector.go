@@ -0,0 +1,372 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// nativeBucketBytes is the estimated size of a native histogram bucket,
+	// i.e. of an entry in a sync.Map with an int key and an *int64 value,
+	// including the internal overhead of the map.
+	nativeBucketBytes = 96
+	// streamBytes is the estimated size of a quantile.Stream of a summary
+	// with a moderate number of stored samples.
+	streamBytes = 1024
+	// stringHeaderBytes is the size of a string header.
+	stringHeaderBytes = int(unsafe.Sizeof(""))
+)
+
+// memoryStats is an estimate of the memory held by a metric or a family of
+// metrics. It only covers the data structures of this package, not any
+// allocator overhead.
+type memoryStats struct {
+	children   int // Number of metrics.
+	buckets    int // Classic and native histogram buckets or summary streams.
+	exemplars  int // Number of stored exemplars.
+	labelBytes int // Total length of label names and values.
+	bytes      int // Total estimated size in bytes.
+}
+
+func (s *memoryStats) add(o memoryStats) {
+	s.children += o.children
+	s.buckets += o.buckets
+	s.exemplars += o.exemplars
+	s.labelBytes += o.labelBytes
+	s.bytes += o.bytes
+}
+
+// memoryEstimator is implemented by the metrics and metric vectors of this
+// package. The estimate has to be cheap to calculate, i.e. it must not depend
+// on walking large data structures, but on counts that are tracked anyway or
+// incrementally.
+type memoryEstimator interface {
+	// estimateMemory returns the name of the metric family and the
+	// estimated memory it holds.
+	estimateMemory() (string, memoryStats)
+}
+
+// memoryTracker keeps running totals of the memory held by the children of a
+// vector, so that they can be read without iterating over the children. The
+// size of a child is added upon its creation and removed upon its deletion by
+// the metricMap. In between, a child whose size can change (new native
+// histogram buckets or exemplars) reports the change directly, see
+// trackedMemory. The size of other children, e.g. gauges and summaries, is
+// fixed upon creation.
+type memoryTracker struct {
+	buckets, exemplars, bytes int64 // Accessed atomically.
+}
+
+func (t *memoryTracker) add(buckets, exemplars, bytes int) {
+	if buckets != 0 {
+		atomic.AddInt64(&t.buckets, int64(buckets))
+	}
+	if exemplars != 0 {
+		atomic.AddInt64(&t.exemplars, int64(exemplars))
+	}
+	if bytes != 0 {
+		atomic.AddInt64(&t.bytes, int64(bytes))
+	}
+}
+
+func (t *memoryTracker) reset() {
+	atomic.StoreInt64(&t.buckets, 0)
+	atomic.StoreInt64(&t.exemplars, 0)
+	atomic.StoreInt64(&t.bytes, 0)
+}
+
+// memoryTrackable is implemented by metrics whose size changes after their
+// creation and which therefore report those changes to the memoryTracker of
+// the vector they belong to.
+type memoryTrackable interface {
+	memoryEstimator
+	setMemoryTracker(*memoryTracker)
+}
+
+// trackedMemory is embedded in metrics implementing memoryTrackable. The
+// tracker is nil for metrics not belonging to a vector (or deleted from it),
+// in which case changes are not reported.
+type trackedMemory struct {
+	tracker atomic.Pointer[memoryTracker]
+}
+
+func (t *trackedMemory) setMemoryTracker(mt *memoryTracker) {
+	t.tracker.Store(mt)
+}
+
+// trackNativeBuckets reports n added (or, if negative, removed) native
+// histogram buckets.
+func (t *trackedMemory) trackNativeBuckets(n int) {
+	if mt := t.tracker.Load(); mt != nil && n != 0 {
+		mt.add(n, 0, n*nativeBucketBytes)
+	}
+}
+
+// trackExemplar reports that the exemplar old (nil if there was none) has
+// been replaced by the exemplar e.
+func (t *trackedMemory) trackExemplar(old, e *dto.Exemplar) {
+	mt := t.tracker.Load()
+	if mt == nil {
+		return
+	}
+	var exemplars int
+	if old == nil {
+		exemplars = 1
+	}
+	mt.add(0, exemplars, exemplarBytes(e)-exemplarBytes(old))
+}
+
+// NewMetricMemoryCollector returns a Collector that exposes an estimate of the
+// memory held by each metric family registered with the provided Registry,
+// partitioned by the label "name":
+//
+//   - "prometheus_metric_memory_children": the number of metrics (children
+//     of vectors).
+//   - "prometheus_metric_memory_buckets": the number of classic and native
+//     histogram buckets, and the number of quantile streams of summaries.
+//   - "prometheus_metric_memory_exemplars": the number of stored exemplars.
+//   - "prometheus_metric_memory_label_bytes": the total length of label
+//     names and values.
+//   - "prometheus_metric_memory_estimated_bytes": the estimated total size of
+//     all of the above and of the data structures holding them.
+//
+// Only the Counters, Gauges, Histograms, and Summaries of this package, their
+// vectors, and wrapped versions of them (see WrapRegistererWith) are covered.
+// Other Collectors (including const metrics created upon collection) are
+// ignored. The estimate is based on running totals maintained by each vector
+// as children are created, deleted, and observed, so that it does not depend
+// on the number of children. The totals are approximate if children are
+// modified concurrently with their deletion.
+//
+// The Collector is usually registered with the Registry it inspects. It is not
+// included in any default registry.
+func NewMetricMemoryCollector(r *Registry) Collector {
+	newDesc := func(name, help string) *Desc {
+		return NewDesc("prometheus_metric_memory_"+name, help, []string{"name"}, nil)
+	}
+	return &metricMemoryCollector{
+		reg:        r,
+		children:   newDesc("children", "Number of metrics in the metric family."),
+		buckets:    newDesc("buckets", "Number of histogram buckets and summary quantile streams in the metric family."),
+		exemplars:  newDesc("exemplars", "Number of exemplars stored in the metric family."),
+		labelBytes: newDesc("label_bytes", "Total length of the label names and values of the metric family in bytes."),
+		bytes:      newDesc("estimated_bytes", "Estimated memory held by the metric family in bytes."),
+	}
+}
+
+type metricMemoryCollector struct {
+	reg                                             *Registry
+	children, buckets, exemplars, labelBytes, bytes *Desc
+}
+
+// Describe implements Collector.
+func (c *metricMemoryCollector) Describe(ch chan<- *Desc) {
+	ch <- c.children
+	ch <- c.buckets
+	ch <- c.exemplars
+	ch <- c.labelBytes
+	ch <- c.bytes
+}
+
+// Collect implements Collector.
+func (c *metricMemoryCollector) Collect(ch chan<- Metric) {
+	// Gather releases the lock before collecting, so it can be taken here.
+	c.reg.mtx.RLock()
+	collectors := make([]Collector, 0, len(c.reg.collectorsByID)+len(c.reg.uncheckedCollectors))
+	for _, collector := range c.reg.collectorsByID {
+		collectors = append(collectors, collector)
+	}
+	collectors = append(collectors, c.reg.uncheckedCollectors...)
+	c.reg.mtx.RUnlock()
+
+	families := map[string]memoryStats{}
+	for _, collector := range collectors {
+		var prefix string
+		for {
+			wc, ok := collector.(*wrappingCollector)
+			if !ok {
+				break
+			}
+			prefix += wc.prefix
+			collector = wc.wrappedCollector
+		}
+		e, ok := collector.(memoryEstimator)
+		if !ok {
+			continue
+		}
+		name, stats := e.estimateMemory()
+		if name == "" {
+			continue
+		}
+		family := families[prefix+name]
+		family.add(stats)
+		families[prefix+name] = family
+	}
+
+	for name, stats := range families {
+		ch <- MustNewConstMetric(c.children, GaugeValue, float64(stats.children), name)
+		ch <- MustNewConstMetric(c.buckets, GaugeValue, float64(stats.buckets), name)
+		ch <- MustNewConstMetric(c.exemplars, GaugeValue, float64(stats.exemplars), name)
+		ch <- MustNewConstMetric(c.labelBytes, GaugeValue, float64(stats.labelBytes), name)
+		ch <- MustNewConstMetric(c.bytes, GaugeValue, float64(stats.bytes), name)
+	}
+}
+
+// estimateMemory implements memoryEstimator. It only reads the running totals
+// of the metricMap and its memoryTracker. The label values of the children are
+// accounted for by the vector. The label names and the constant labels are
+// shared by all children and therefore accounted for only once.
+func (m *MetricVec) estimateMemory() (string, memoryStats) {
+	m.mtx.RLock()
+	stats := memoryStats{
+		children:   m.children,
+		labelBytes: m.labelValueBytes,
+	}
+	m.mtx.RUnlock()
+	stats.buckets = int(atomic.LoadInt64(&m.memory.buckets))
+	stats.exemplars = int(atomic.LoadInt64(&m.memory.exemplars))
+	stats.bytes = int(atomic.LoadInt64(&m.memory.bytes))
+
+	var numLabels int
+	if m.desc.variableLabels != nil {
+		numLabels = len(m.desc.variableLabels.names)
+		for _, name := range m.desc.variableLabels.names {
+			stats.labelBytes += len(name)
+		}
+	}
+	for _, lp := range m.desc.constLabelPairs {
+		stats.labelBytes += len(lp.GetName()) + len(lp.GetValue())
+	}
+	// The label values are referenced by the metricMap and by the label
+	// pairs of the metric, but the string data is shared.
+	stats.bytes += stats.labelBytes +
+		stats.children*(int(unsafe.Sizeof(metricWithLabelValues{}))+numLabels*stringHeaderBytes)
+	return m.desc.fqName, stats
+}
+
+// estimateMemory implements memoryEstimator.
+func (c *counter) estimateMemory() (string, memoryStats) {
+	stats := memoryStats{children: 1, bytes: int(unsafe.Sizeof(*c))}
+	stats.addLabelPairs(c.labelPairs)
+	if c.createdTs != nil {
+		stats.bytes += int(unsafe.Sizeof(timestamppb.Timestamp{}))
+	}
+	if e, ok := c.exemplar.Load().(*dto.Exemplar); ok && e != nil {
+		stats.addExemplar(e)
+	}
+	return c.desc.fqName, stats
+}
+
+// estimateMemory implements memoryEstimator.
+func (g *gauge) estimateMemory() (string, memoryStats) {
+	stats := memoryStats{children: 1, bytes: int(unsafe.Sizeof(*g))}
+	stats.addLabelPairs(g.labelPairs)
+	return g.desc.fqName, stats
+}
+
+// estimateMemory implements memoryEstimator. The number of native histogram
+// buckets is tracked by the histogramCounts anyway.
+func (h *histogram) estimateMemory() (string, memoryStats) {
+	classic := len(h.upperBounds)
+	var native int
+	for _, hc := range h.counts {
+		native += int(atomic.LoadUint32(&hc.nativeHistogramBucketsNumber))
+	}
+	stats := memoryStats{
+		children: 1,
+		buckets:  classic + native,
+		bytes: int(unsafe.Sizeof(histogram{})) +
+			2*(int(unsafe.Sizeof(histogramCounts{}))+8*classic) + // Hot and cold counts.
+			8*classic + // Upper bounds.
+			len(h.exemplars)*int(unsafe.Sizeof(atomic.Value{})) +
+			native*nativeBucketBytes,
+	}
+	stats.addLabelPairs(h.labelPairs)
+	for i := range h.exemplars {
+		if e, ok := h.exemplars[i].Load().(*dto.Exemplar); ok && e != nil {
+			stats.addExemplar(e)
+		}
+	}
+	h.nativeExemplars.Lock()
+	for _, e := range h.nativeExemplars.exemplars {
+		stats.addExemplar(e)
+	}
+	h.nativeExemplars.Unlock()
+	return h.desc.fqName, stats
+}
+
+// estimateMemory implements memoryEstimator.
+func (s *summary) estimateMemory() (string, memoryStats) {
+	s.bufMtx.Lock()
+	s.mtx.Lock()
+	bufBytes := 8 * (cap(s.hotBuf) + cap(s.coldBuf))
+	streams := len(s.streams)
+	s.mtx.Unlock()
+	s.bufMtx.Unlock()
+
+	stats := memoryStats{
+		children: 1,
+		buckets:  streams,
+		bytes: int(unsafe.Sizeof(summary{})) +
+			len(s.objectives)*(16+8) + // Objectives map and sorted objectives.
+			bufBytes +
+			streams*streamBytes,
+	}
+	stats.addLabelPairs(s.labelPairs)
+	return s.desc.fqName, stats
+}
+
+// estimateMemory implements memoryEstimator.
+func (s *noObjectivesSummary) estimateMemory() (string, memoryStats) {
+	stats := memoryStats{
+		children: 1,
+		bytes:    int(unsafe.Sizeof(noObjectivesSummary{})) + 2*int(unsafe.Sizeof(summaryCounts{})),
+	}
+	stats.addLabelPairs(s.labelPairs)
+	return s.desc.fqName, stats
+}
+
+// addLabelPairs accounts for the provided label pairs of a metric.
+func (s *memoryStats) addLabelPairs(lps []*dto.LabelPair) {
+	for _, lp := range lps {
+		n := len(lp.GetName()) + len(lp.GetValue())
+		s.labelBytes += n
+		s.bytes += n + 8 + int(unsafe.Sizeof(dto.LabelPair{})) + 2*stringHeaderBytes
+	}
+}
+
+// addExemplar accounts for the provided exemplar. Its labels are not part of
+// the label bytes.
+func (s *memoryStats) addExemplar(e *dto.Exemplar) {
+	s.exemplars++
+	s.bytes += exemplarBytes(e)
+}
+
+// exemplarBytes returns the estimated size of e, or 0 if e is nil.
+func exemplarBytes(e *dto.Exemplar) int {
+	if e == nil {
+		return 0
+	}
+	n := int(unsafe.Sizeof(dto.Exemplar{})) + int(unsafe.Sizeof(timestamppb.Timestamp{})) + 8
+	for _, lp := range e.GetLabel() {
+		n += len(lp.GetName()) + len(lp.GetValue()) + 8 +
+			int(unsafe.Sizeof(dto.LabelPair{})) + 2*stringHeaderBytes
+	}
+	return n
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestMetricMemoryCollector(t *testing.T) {
+	reg := NewRegistry()
+	reg.MustRegister(NewMetricMemoryCollector(reg))
+
+	counters := NewCounterVec(CounterOpts{Name: "counters", Help: "help"}, []string{"code"})
+	histograms := NewHistogramVec(HistogramOpts{
+		Name:                        "histograms",
+		Help:                        "help",
+		Buckets:                     []float64{1, 2, 3},
+		NativeHistogramBucketFactor: 1.1,
+	}, []string{"method"})
+	summary := NewSummary(SummaryOpts{
+		Name:       "summary",
+		Help:       "help",
+		Objectives: map[float64]float64{0.5: 0.05},
+		AgeBuckets: 3,
+	})
+	gauge := NewGauge(GaugeOpts{Name: "gauge", Help: "help", ConstLabels: Labels{"a": "bc"}})
+	summaries := NewSummaryVec(SummaryOpts{
+		Name:       "summaries",
+		Help:       "help",
+		Objectives: map[float64]float64{0.5: 0.05},
+		AgeBuckets: 3,
+	}, []string{"method"})
+	gauges := NewGaugeVec(GaugeOpts{Name: "gauges", Help: "help"}, []string{"a"})
+	reg.MustRegister(counters, histograms, summary, summaries, gauges)
+	WrapRegistererWithPrefix("wrapped_", reg).MustRegister(gauge)
+
+	counters.WithLabelValues("200").Inc()
+	counters.WithLabelValues("404").(ExemplarAdder).AddWithExemplar(1, Labels{"trace_id": "abc"})
+	counters.WithLabelValues("500").Inc()
+	counters.DeleteLabelValues("500")
+	histograms.WithLabelValues("GET").Observe(1.5)
+	histograms.WithLabelValues("GET").Observe(10)
+	summaries.WithLabelValues("GET").Observe(1)
+	summaries.WithLabelValues("POST").Observe(2)
+	gauges.WithLabelValues("bc").Set(1)
+
+	got := gatherMemoryStats(t, reg)
+	want := map[string]map[string]float64{
+		"counters": {
+			"children":    2,
+			"buckets":     0,
+			"exemplars":   1,
+			"label_bytes": float64(len("code") + len("200") + len("404")),
+		},
+		"histograms": {
+			"children": 1,
+			// 3 classic and 2 native buckets.
+			"buckets":     5,
+			"exemplars":   0,
+			"label_bytes": float64(len("method") + len("GET")),
+		},
+		"summary": {
+			"children":    1,
+			"buckets":     3,
+			"label_bytes": 0,
+		},
+		"summaries": {
+			"children":    2,
+			"buckets":     6,
+			"label_bytes": float64(len("method") + len("GET") + len("POST")),
+		},
+		"wrapped_gauge": {
+			"children":    1,
+			"label_bytes": 3,
+		},
+		"gauges": {
+			"children":    1,
+			"label_bytes": 3,
+		},
+	}
+	for family, metrics := range want {
+		for metric, value := range metrics {
+			if got[family][metric] != value {
+				t.Errorf("%s of %s: got %v, want %v", metric, family, got[family][metric], value)
+			}
+		}
+		if got[family]["estimated_bytes"] <= got[family]["label_bytes"] {
+			t.Errorf("implausible estimated bytes of %s: %v", family, got[family]["estimated_bytes"])
+		}
+	}
+	// The children of vectors are estimated like standalone metrics, save
+	// for their labels.
+	if got["summaries"]["estimated_bytes"]-got["summaries"]["label_bytes"] < 2*(got["summary"]["estimated_bytes"]-got["summary"]["label_bytes"]) {
+		t.Errorf("estimated bytes of summary vector %v too low compared to summary %v", got["summaries"], got["summary"])
+	}
+	if got["gauges"]["estimated_bytes"]-got["gauges"]["label_bytes"] < got["wrapped_gauge"]["estimated_bytes"]-got["wrapped_gauge"]["label_bytes"] {
+		t.Errorf("estimated bytes of gauge vector %v too low compared to gauge %v", got["gauges"], got["wrapped_gauge"])
+	}
+	if _, ok := got["prometheus_metric_memory_children"]; ok {
+		t.Error("memory collector accounts for itself")
+	}
+
+	// Deleting and resetting is tracked, too.
+	before := got["counters"]["estimated_bytes"]
+	counters.DeleteLabelValues("200")
+	got = gatherMemoryStats(t, reg)
+	if got["counters"]["children"] != 1 || got["counters"]["label_bytes"] != float64(len("code")+len("404")) {
+		t.Errorf("unexpected stats after delete: %v", got["counters"])
+	}
+	if got["counters"]["estimated_bytes"] >= before {
+		t.Errorf("estimated bytes did not decrease after delete: %v >= %v", got["counters"]["estimated_bytes"], before)
+	}
+	counters.Reset()
+	got = gatherMemoryStats(t, reg)
+	if got["counters"]["children"] != 0 || got["counters"]["label_bytes"] != float64(len("code")) {
+		t.Errorf("unexpected stats after reset: %v", got["counters"])
+	}
+}
+
+func TestMemoryTrackerMatchesChildren(t *testing.T) {
+	histograms := NewHistogramVec(HistogramOpts{
+		Name:                           "histograms",
+		Help:                           "help",
+		Buckets:                        []float64{1},
+		NativeHistogramBucketFactor:    1.1,
+		NativeHistogramMaxBucketNumber: 10,
+		NativeHistogramMaxExemplars:    3,
+	}, []string{"method"})
+	counters := NewCounterVec(CounterOpts{Name: "counters", Help: "help"}, []string{"code"})
+	summaries := NewSummaryVec(SummaryOpts{
+		Name:       "summaries",
+		Help:       "help",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01},
+	}, []string{"method"})
+	gauges := NewGaugeVec(GaugeOpts{Name: "gauges", Help: "help"}, []string{"a"})
+
+	// recount sums up the estimates of all children the way the running
+	// totals are maintained.
+	recount := func(m *MetricVec) (buckets, exemplars, bytes int) {
+		m.mtx.RLock()
+		defer m.mtx.RUnlock()
+		for _, metrics := range m.metrics {
+			for _, metric := range metrics {
+				_, stats := metric.metric.(memoryEstimator).estimateMemory()
+				buckets += stats.buckets
+				exemplars += stats.exemplars
+				bytes += stats.bytes - stats.labelBytes
+			}
+		}
+		return buckets, exemplars, bytes
+	}
+	check := func(step string, m *MetricVec) {
+		t.Helper()
+		wantBuckets, wantExemplars, wantBytes := recount(m)
+		if got := int(m.memory.buckets); got != wantBuckets {
+			t.Errorf("%s: got %d tracked buckets, want %d", step, got, wantBuckets)
+		}
+		if got := int(m.memory.exemplars); got != wantExemplars {
+			t.Errorf("%s: got %d tracked exemplars, want %d", step, got, wantExemplars)
+		}
+		if got := int(m.memory.bytes); got != wantBytes {
+			t.Errorf("%s: got %d tracked bytes, want %d", step, got, wantBytes)
+		}
+	}
+
+	h := histograms.WithLabelValues("GET")
+	check("create", histograms.MetricVec)
+	for i := 1; i <= 100; i++ {
+		// Exceeding the bucket limit widens the buckets.
+		h.(ExemplarObserver).ObserveWithExemplar(float64(i), Labels{"id": strings.Repeat("x", i%7)})
+	}
+	check("observe", histograms.MetricVec)
+	histograms.WithLabelValues("POST").Observe(-3)
+	if err := h.(Metric).Write(&dto.Metric{}); err != nil {
+		t.Fatal(err)
+	}
+	check("write", histograms.MetricVec)
+	histograms.DeleteLabelValues("GET")
+	check("delete", histograms.MetricVec)
+	// Observing a deleted histogram does not affect the vector anymore.
+	h.Observe(1e6)
+	check("observe deleted", histograms.MetricVec)
+	histograms.Reset()
+	check("reset", histograms.MetricVec)
+
+	c := counters.WithLabelValues("200").(ExemplarAdder)
+	c.AddWithExemplar(1, Labels{"id": "a"})
+	c.AddWithExemplar(1, Labels{"id": "abc"})
+	counters.WithLabelValues("404").Inc()
+	check("counter exemplars", counters.MetricVec)
+	if got := counters.memory.exemplars; got != 1 {
+		t.Errorf("got %d tracked counter exemplars, want 1", got)
+	}
+
+	for i := 0; i < 1000; i++ {
+		summaries.WithLabelValues("GET").Observe(float64(i))
+	}
+	summaries.WithLabelValues("POST").Observe(1)
+	check("summaries", summaries.MetricVec)
+	if got := summaries.memory.buckets; got != 2*DefAgeBuckets {
+		t.Errorf("got %d tracked summary streams, want %d", got, 2*DefAgeBuckets)
+	}
+	summaries.DeleteLabelValues("GET")
+	check("delete summary", summaries.MetricVec)
+
+	gauges.WithLabelValues("x").Set(1)
+	gauges.WithLabelValues("y").Set(2)
+	check("gauges", gauges.MetricVec)
+	if gauges.memory.bytes == 0 {
+		t.Error("gauges not tracked")
+	}
+	gauges.Reset()
+	check("reset gauges", gauges.MetricVec)
+}
+
+// gatherMemoryStats returns the values of the metrics of a
+// metricMemoryCollector by family name and metric name (without the prefix).
+func gatherMemoryStats(t *testing.T, g Gatherer) map[string]map[string]float64 {
+	t.Helper()
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := map[string]map[string]float64{}
+	for _, mf := range mfs {
+		name, ok := strings.CutPrefix(mf.GetName(), "prometheus_metric_memory_")
+		if !ok {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			family := m.GetLabel()[0].GetValue()
+			if stats[family] == nil {
+				stats[family] = map[string]float64{}
+			}
+			stats[family][name] = m.GetGauge().GetValue()
+		}
+	}
+	return stats
+}
@@ -93,6 +93,11 @@ type Opts struct {
 	// https://prometheus.io/docs/instrumenting/writing_exporters/#target-labels-not-static-scraped-labels
 	ConstLabels Labels
 
+	// ConstLabelSet is an alternative to ConstLabels for callers holding the
+	// constant labels as LabelSet. Both are merged, with the value in
+	// ConstLabelSet taking precedence if a label name occurs in both.
+	ConstLabelSet LabelSet
+
 	// now is for testing purposes, by default it's time.Now.
 	now func() time.Time
 }
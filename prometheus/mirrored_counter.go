@@ -0,0 +1,387 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MirroredCounter is a Metric that mirrors a cumulative value maintained
+// outside of the instrumented process, e.g. a counter read from an upstream
+// system. Rather than being incremented, it is set to the absolute total
+// observed upstream.
+//
+// If an observed total is lower than the previous one, the MirroredCounter
+// assumes that the upstream counter was reset. By default, it then exposes the
+// new total as is and moves its created timestamp to the time of the
+// reset. With AccumulateResets set in the MirroredCounterOpts, it instead adds
+// up the totals across resets, so that the exposed value stays monotonic and
+// the created timestamp is left untouched.
+//
+// In either case, the number of detected resets is exposed as a companion
+// counter named like the MirroredCounter, but with a "_resets_total" suffix
+// (replacing a "_total" suffix, if any), e.g. "upstream_requests_resets_total"
+// for "upstream_requests_total".
+//
+// To create MirroredCounter instances, use NewMirroredCounter.
+type MirroredCounter interface {
+	Metric
+	Collector
+
+	// Set sets the mirrored counter to the provided observed total. It
+	// panics if the value is < 0 or NaN.
+	Set(float64)
+	// Resets returns the number of upstream resets detected so far.
+	Resets() uint64
+}
+
+// MirroredCounterOpts bundles the options for creating a MirroredCounter. It is
+// mandatory to set CounterOpts, see there for mandatory fields.
+type MirroredCounterOpts struct {
+	CounterOpts
+
+	// AccumulateResets controls what happens if an upstream reset is
+	// detected. If false (the default), the newly observed total is exposed
+	// as is and the created timestamp is set to the time of the reset. If
+	// true, the totals observed before the reset are added to all
+	// subsequently observed totals, so that the exposed value never
+	// decreases.
+	AccumulateResets bool
+}
+
+// MirroredCounterVecOpts bundles the options to create a MirroredCounterVec
+// metric. It is mandatory to set MirroredCounterOpts, see there for mandatory
+// fields. VariableLabels is optional and can safely be left to its default
+// value.
+type MirroredCounterVecOpts struct {
+	MirroredCounterOpts
+
+	// VariableLabels are used to partition the metric vector by the given set
+	// of labels. Each label value will be constrained with the optional Constraint
+	// function, if provided.
+	VariableLabels ConstrainableLabels
+}
+
+// NewMirroredCounter creates a new MirroredCounter based on the provided
+// MirroredCounterOpts.
+func NewMirroredCounter(opts MirroredCounterOpts) MirroredCounter {
+	desc := NewDesc(
+		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		opts.Help,
+		nil,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
+	)
+	if opts.now == nil {
+		opts.now = time.Now
+	}
+	return newMirroredCounter(desc, newMirroredCounterResetsDesc(desc), desc.constLabelPairs, opts)
+}
+
+// newMirroredCounterResetsDesc returns the Desc of the companion counter
+// exposing the number of resets detected by the MirroredCounter(s) described
+// by desc.
+func newMirroredCounterResetsDesc(desc *Desc) *Desc {
+	constLabels := make(Labels, len(desc.constLabelPairs))
+	for _, lp := range desc.constLabelPairs {
+		constLabels[lp.GetName()] = lp.GetValue()
+	}
+	return V2.NewDesc(
+		strings.TrimSuffix(desc.fqName, "_total")+"_resets_total",
+		fmt.Sprintf("Number of upstream resets detected for %s.", desc.fqName),
+		desc.variableLabels,
+		constLabels,
+	)
+}
+
+func newMirroredCounter(desc, resetsDesc *Desc, labelPairs []*dto.LabelPair, opts MirroredCounterOpts) *mirroredCounter {
+	createdTs := timestamppb.New(opts.now())
+	return &mirroredCounter{
+		desc:       desc,
+		resetsDesc: resetsDesc,
+		labelPairs: labelPairs,
+		accumulate: opts.AccumulateResets,
+		startTs:    createdTs,
+		createdTs:  createdTs,
+		now:        opts.now,
+	}
+}
+
+type mirroredCounter struct {
+	desc       *Desc
+	resetsDesc *Desc
+	labelPairs []*dto.LabelPair
+	accumulate bool
+	// startTs is the creation time of the companion resets counter, which
+	// is never reset.
+	startTs *timestamppb.Timestamp
+
+	mtx sync.Mutex // Protects the fields below.
+	// last is the most recently observed upstream total.
+	last float64
+	// offset is the sum of all totals observed right before a reset. It is
+	// only used if accumulate is true.
+	offset    float64
+	resets    uint64
+	createdTs *timestamppb.Timestamp
+
+	// now is for testing purposes, by default it's time.Now.
+	now func() time.Time
+}
+
+func (c *mirroredCounter) Desc() *Desc {
+	return c.desc
+}
+
+// Describe implements Collector.
+func (c *mirroredCounter) Describe(ch chan<- *Desc) {
+	ch <- c.desc
+	ch <- c.resetsDesc
+}
+
+// Collect implements Collector.
+func (c *mirroredCounter) Collect(ch chan<- Metric) {
+	ch <- c
+	ch <- c.resetsMetric()
+}
+
+// resetsMetric returns the companion counter exposing the number of resets
+// detected so far.
+func (c *mirroredCounter) resetsMetric() Metric {
+	m := &dto.Metric{}
+	// populateMetric cannot fail for a CounterValue.
+	_ = populateMetric(CounterValue, float64(c.Resets()), c.labelPairs, nil, m, c.startTs)
+	return &constMetric{desc: c.resetsDesc, metric: m}
+}
+
+func (c *mirroredCounter) Set(v float64) {
+	if math.IsNaN(v) {
+		panic(errors.New("mirrored counter cannot be set to NaN"))
+	}
+	if v < 0 {
+		panic(errors.New("mirrored counter cannot be set to a negative value"))
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if v < c.last {
+		c.resets++
+		if c.accumulate {
+			c.offset += c.last
+		} else {
+			c.createdTs = timestamppb.New(c.now())
+		}
+	}
+	c.last = v
+}
+
+func (c *mirroredCounter) Resets() uint64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.resets
+}
+
+func (c *mirroredCounter) Write(out *dto.Metric) error {
+	c.mtx.Lock()
+	val := c.offset + c.last
+	createdTs := c.createdTs
+	c.mtx.Unlock()
+
+	return populateMetric(CounterValue, val, c.labelPairs, nil, out, createdTs)
+}
+
+// MirroredCounterVec is a Collector that bundles a set of MirroredCounters that
+// all share the same Desc, but have different values for their variable
+// labels. This is used if the mirrored upstream counters are partitioned by
+// various dimensions. Create instances with NewMirroredCounterVec.
+type MirroredCounterVec struct {
+	*MetricVec
+	resetsDesc *Desc
+}
+
+// NewMirroredCounterVec creates a new MirroredCounterVec based on the provided
+// MirroredCounterOpts and partitioned by the given label names.
+func NewMirroredCounterVec(opts MirroredCounterOpts, labelNames []string) *MirroredCounterVec {
+	return V2.NewMirroredCounterVec(MirroredCounterVecOpts{
+		MirroredCounterOpts: opts,
+		VariableLabels:      UnconstrainedLabels(labelNames),
+	})
+}
+
+// NewMirroredCounterVec creates a new MirroredCounterVec based on the provided
+// MirroredCounterVecOpts.
+func (v2) NewMirroredCounterVec(opts MirroredCounterVecOpts) *MirroredCounterVec {
+	desc := V2.NewDesc(
+		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		opts.Help,
+		opts.VariableLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
+	)
+	if opts.now == nil {
+		opts.now = time.Now
+	}
+	resetsDesc := newMirroredCounterResetsDesc(desc)
+	return &MirroredCounterVec{
+		MetricVec: NewMetricVec(desc, func(lvs ...string) Metric {
+			if len(lvs) != len(desc.variableLabels.names) {
+				panic(makeInconsistentCardinalityError(desc.fqName, desc.variableLabels.names, lvs))
+			}
+			return newMirroredCounter(desc, resetsDesc, MakeLabelPairs(desc, lvs), opts.MirroredCounterOpts)
+		}),
+		resetsDesc: resetsDesc,
+	}
+}
+
+// Describe implements Collector. In addition to the Desc of the
+// MirroredCounters, it sends the Desc of the companion resets counter.
+func (v *MirroredCounterVec) Describe(ch chan<- *Desc) {
+	v.MetricVec.Describe(ch)
+	ch <- v.resetsDesc
+}
+
+// Collect implements Collector. For each MirroredCounter in the vector, it
+// also collects the companion resets counter.
+func (v *MirroredCounterVec) Collect(ch chan<- Metric) {
+	v.metricMap.mtx.RLock()
+	defer v.metricMap.mtx.RUnlock()
+
+	for _, metrics := range v.metricMap.metrics {
+		for _, metric := range metrics {
+			metric.metric.(*mirroredCounter).Collect(ch)
+		}
+	}
+}
+
+// GetMetricWithLabelValues returns the MirroredCounter for the given slice of
+// label values (same order as the variable labels in Desc). If that
+// combination of label values is accessed for the first time, a new
+// MirroredCounter is created. See CounterVec.GetMetricWithLabelValues for the
+// implications of keeping the returned MirroredCounter for later use.
+func (v *MirroredCounterVec) GetMetricWithLabelValues(lvs ...string) (MirroredCounter, error) {
+	metric, err := v.MetricVec.GetMetricWithLabelValues(lvs...)
+	if metric != nil {
+		return metric.(MirroredCounter), err
+	}
+	return nil, err
+}
+
+// GetMetricWith returns the MirroredCounter for the given Labels map (the label
+// names must match those of the variable labels in Desc). If that label map is
+// accessed for the first time, a new MirroredCounter is created.
+func (v *MirroredCounterVec) GetMetricWith(labels Labels) (MirroredCounter, error) {
+	metric, err := v.MetricVec.GetMetricWith(labels)
+	if metric != nil {
+		return metric.(MirroredCounter), err
+	}
+	return nil, err
+}
+
+// WithLabelValues works as GetMetricWithLabelValues, but panics where
+// GetMetricWithLabelValues would have returned an error. Not returning an
+// error allows shortcuts like
+//
+//	myVec.WithLabelValues("eu-west", "db").Set(upstreamTotal)
+func (v *MirroredCounterVec) WithLabelValues(lvs ...string) MirroredCounter {
+	c, err := v.GetMetricWithLabelValues(lvs...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// With works as GetMetricWith, but panics where GetMetricWithLabels would have
+// returned an error. Not returning an error allows shortcuts like
+//
+//	myVec.With(prometheus.Labels{"region": "eu-west", "service": "db"}).Set(upstreamTotal)
+func (v *MirroredCounterVec) With(labels Labels) MirroredCounter {
+	c, err := v.GetMetricWith(labels)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// GetMetricWithLabelSet works like GetMetricWith, but with the labels provided
+// as LabelSet, which avoids allocations on hot paths.
+func (v *MirroredCounterVec) GetMetricWithLabelSet(ls LabelSet) (MirroredCounter, error) {
+	metric, err := v.MetricVec.GetMetricWithLabelSet(ls)
+	if metric != nil {
+		return metric.(MirroredCounter), err
+	}
+	return nil, err
+}
+
+// WithLabelSet works as GetMetricWithLabelSet, but panics where
+// GetMetricWithLabelSet would have returned an error. A LabelSet can be created
+// once and reused, like
+//
+//	notFound := prometheus.NewLabelSet("code", "404", "method", "GET")
+//	myVec.WithLabelSet(notFound).Set(42)
+func (v *MirroredCounterVec) WithLabelSet(ls LabelSet) MirroredCounter {
+	c, err := v.GetMetricWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// CurryWith returns a vector curried with the provided labels, i.e. the
+// returned vector has those labels pre-set for all labeled operations performed
+// on it. See CounterVec.CurryWith for details.
+func (v *MirroredCounterVec) CurryWith(labels Labels) (*MirroredCounterVec, error) {
+	vec, err := v.MetricVec.CurryWith(labels)
+	if vec != nil {
+		return &MirroredCounterVec{vec, v.resetsDesc}, err
+	}
+	return nil, err
+}
+
+// MustCurryWith works as CurryWith but panics where CurryWith would have
+// returned an error.
+func (v *MirroredCounterVec) MustCurryWith(labels Labels) *MirroredCounterVec {
+	vec, err := v.CurryWith(labels)
+	if err != nil {
+		panic(err)
+	}
+	return vec
+}
+
+// CurryWithLabelSet works like CurryWith, but with the labels provided as
+// LabelSet.
+func (v *MirroredCounterVec) CurryWithLabelSet(ls LabelSet) (*MirroredCounterVec, error) {
+	vec, err := v.MetricVec.CurryWithLabelSet(ls)
+	if vec != nil {
+		return &MirroredCounterVec{vec, v.resetsDesc}, err
+	}
+	return nil, err
+}
+
+// MustCurryWithLabelSet works as CurryWithLabelSet but panics where
+// CurryWithLabelSet would have returned an error.
+func (v *MirroredCounterVec) MustCurryWithLabelSet(ls LabelSet) *MirroredCounterVec {
+	vec, err := v.CurryWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return vec
+}
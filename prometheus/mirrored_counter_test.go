@@ -0,0 +1,198 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestMirroredCounterSet(t *testing.T) {
+	start := time.Now()
+	now := start
+
+	for _, tc := range []struct {
+		name          string
+		accumulate    bool
+		expectedValue float64
+		expectedCT    func() time.Time
+	}{
+		{
+			name:          "without accumulation",
+			expectedValue: 5,
+			expectedCT:    func() time.Time { return start.Add(time.Minute) },
+		},
+		{
+			name:          "with accumulation",
+			accumulate:    true,
+			expectedValue: 47,
+			expectedCT:    func() time.Time { return start },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now = start
+			c := NewMirroredCounter(MirroredCounterOpts{
+				CounterOpts: CounterOpts{
+					Name:        "test",
+					Help:        "test help",
+					ConstLabels: Labels{"a": "1"},
+					now:         func() time.Time { return now },
+				},
+				AccumulateResets: tc.accumulate,
+			})
+
+			c.Set(10)
+			c.Set(42)
+			c.Set(42)
+			now = start.Add(time.Minute)
+			c.Set(5) // Upstream reset.
+
+			if expected, got := uint64(1), c.Resets(); expected != got {
+				t.Errorf("expected %d resets, got %d", expected, got)
+			}
+
+			m := &dto.Metric{}
+			if err := c.Write(m); err != nil {
+				t.Fatal(err)
+			}
+			expected := &dto.Metric{
+				Label: []*dto.LabelPair{
+					{Name: proto.String("a"), Value: proto.String("1")},
+				},
+				Counter: &dto.Counter{
+					Value:            proto.Float64(tc.expectedValue),
+					CreatedTimestamp: timestamppb.New(tc.expectedCT()),
+				},
+			}
+			if !proto.Equal(expected, m) {
+				t.Errorf("expected %q, got %q", expected, m)
+			}
+		})
+	}
+}
+
+func TestMirroredCounterSetNegative(t *testing.T) {
+	c := NewMirroredCounter(MirroredCounterOpts{
+		CounterOpts: CounterOpts{Name: "test", Help: "test help"},
+	})
+
+	defer func() {
+		if e := recover(); e == nil {
+			t.Error("expected panic when setting a negative value")
+		}
+	}()
+	c.Set(-1)
+}
+
+func TestMirroredCounterSetNaN(t *testing.T) {
+	c := NewMirroredCounter(MirroredCounterOpts{
+		CounterOpts: CounterOpts{Name: "test", Help: "test help"},
+	})
+
+	defer func() {
+		e := recover()
+		if e == nil {
+			t.Fatal("expected panic when setting NaN")
+		}
+		if err, ok := e.(error); !ok || !strings.Contains(err.Error(), "NaN") {
+			t.Errorf("expected panic mentioning NaN, got %v", e)
+		}
+	}()
+	c.Set(math.NaN())
+}
+
+func TestMirroredCounterResetsExposed(t *testing.T) {
+	c := NewMirroredCounter(MirroredCounterOpts{
+		CounterOpts: CounterOpts{Name: "upstream_requests_total", Help: "help"},
+	})
+	vec := NewMirroredCounterVec(MirroredCounterOpts{
+		CounterOpts: CounterOpts{Name: "upstream_bytes", Help: "help"},
+	}, []string{"label"})
+	reg := NewPedanticRegistry()
+	reg.MustRegister(c, vec)
+
+	c.Set(10)
+	c.Set(2)
+	c.Set(1)
+	vec.WithLabelValues("a").Set(3)
+	vec.WithLabelValues("b").Set(3)
+	vec.WithLabelValues("b").Set(1)
+	curried := vec.MustCurryWith(Labels{"label": "c"})
+	curried.WithLabelValues().Set(1)
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			name := mf.GetName()
+			for _, lp := range m.GetLabel() {
+				name += "," + lp.GetName() + "=" + lp.GetValue()
+			}
+			got[name] = m.GetCounter().GetValue()
+		}
+	}
+	expected := map[string]float64{
+		"upstream_requests_total":             1,
+		"upstream_requests_resets_total":      2,
+		"upstream_bytes,label=a":              3,
+		"upstream_bytes,label=b":              1,
+		"upstream_bytes,label=c":              1,
+		"upstream_bytes_resets_total,label=a": 0,
+		"upstream_bytes_resets_total,label=b": 1,
+		"upstream_bytes_resets_total,label=c": 0,
+	}
+	if fmt.Sprint(expected) != fmt.Sprint(got) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestMirroredCounterVec(t *testing.T) {
+	now := time.Now()
+
+	vec := NewMirroredCounterVec(MirroredCounterOpts{
+		CounterOpts: CounterOpts{
+			Name: "test",
+			Help: "test help",
+			now:  func() time.Time { return now },
+		},
+	}, []string{"label"})
+
+	vec.WithLabelValues("1").Set(3)
+	vec.With(Labels{"label": "2"}).Set(7)
+	expected := map[string]time.Time{"1": now, "2": now}
+
+	now = now.Add(time.Hour)
+	vec.WithLabelValues("2").Set(1)
+	expected["2"] = now
+
+	now = now.Add(time.Hour)
+	expectCTsForMetricVecValues(t, vec.MetricVec, dto.MetricType_COUNTER, expected)
+
+	if expected, got := uint64(0), vec.WithLabelValues("1").Resets(); expected != got {
+		t.Errorf("expected %d resets, got %d", expected, got)
+	}
+	if expected, got := uint64(1), vec.WithLabelValues("2").Resets(); expected != got {
+		t.Errorf("expected %d resets, got %d", expected, got)
+	}
+}
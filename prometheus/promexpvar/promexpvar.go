@@ -0,0 +1,254 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package promexpvar exposes metrics gathered from a prometheus.Gatherer in the
+// JSON format of the expvar package. It is the reverse of
+// collectors.NewExpvarCollector and allows to migrate instrumentation from
+// expvar to client_golang without breaking existing consumers of /debug/vars.
+//
+// Each metric family is mapped to a JSON value as follows:
+//
+//   - Counters, gauges, and untyped metrics become numbers.
+//   - Summaries become objects with "count", "sum", and "quantiles", the
+//     latter mapping each quantile to its value.
+//   - Histograms become objects with "count", "sum", and "buckets", the latter
+//     mapping each upper bound to its cumulative count. Native histograms
+//     without classic buckets only have "count" and "sum".
+//   - If a family has labels, the values above are nested in objects keyed by
+//     the label values, one level per label name in lexicographical order of
+//     the label names. For example, a counter with the labels "code" and
+//     "method" becomes {"200": {"get": 1, "post": 2}}.
+//
+// As JSON has no representation for them, NaN and infinite values are encoded
+// as the strings "NaN", "+Inf", and "-Inf".
+package promexpvar
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Opts selects the exported metric families.
+type Opts struct {
+	// Families are the names of the metric families to export. If empty,
+	// Value, Func, and Handler export all gathered families. Publish
+	// requires at least one family.
+	Families []string
+	// Prefix is prepended to the family names to form the expvar keys.
+	Prefix string
+}
+
+// Value gathers metrics from g and returns the selected families keyed by
+// their expvar keys. Families returned by g despite a gathering error are
+// still exported, along with the error.
+func Value(g prometheus.Gatherer, opts Opts) (map[string]any, error) {
+	mfs, err := g.Gather()
+
+	var selected map[string]struct{}
+	if len(opts.Families) > 0 {
+		selected = make(map[string]struct{}, len(opts.Families))
+		for _, name := range opts.Families {
+			selected[name] = struct{}{}
+		}
+	}
+
+	result := make(map[string]any, len(mfs))
+	for _, mf := range mfs {
+		if selected != nil {
+			if _, ok := selected[mf.GetName()]; !ok {
+				continue
+			}
+		}
+		result[opts.Prefix+mf.GetName()] = familyValue(mf)
+	}
+	return result, err
+}
+
+// Func returns an expvar.Func that gathers metrics from g on each call and
+// returns the selected families as described for Value. Gathering errors are
+// ignored.
+func Func(g prometheus.Gatherer, opts Opts) expvar.Func {
+	return func() any {
+		v, _ := Value(g, opts)
+		return v
+	}
+}
+
+// Publish publishes each family in opts.Families as a separate expvar
+// variable, named by the family name with opts.Prefix prepended. Each variable
+// gathers metrics from g whenever it is read, so consider using Handler if many
+// families are exported from an expensive Gatherer. Like expvar.Publish,
+// Publish panics if a variable of the same name is already published. It also
+// panics if opts.Families is empty.
+func Publish(g prometheus.Gatherer, opts Opts) {
+	if len(opts.Families) == 0 {
+		panic("promexpvar: Publish requires at least one family")
+	}
+	for _, name := range opts.Families {
+		expvar.Publish(opts.Prefix+name, expvar.Func(func() any {
+			v, _ := Value(g, Opts{Families: []string{name}})
+			return v[name]
+		}))
+	}
+}
+
+// Handler returns an http.Handler that serves a JSON object like
+// expvar.Handler, i.e. all published expvar variables, but additionally the
+// families selected by opts, gathered from g once per request. A published
+// expvar variable with the same key as an exported family is shadowed by the
+// family. Gathering errors are ignored, so that the handler serves as much as
+// possible, just like expvar.Handler.
+func Handler(g prometheus.Gatherer, opts Opts) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		families, _ := Value(g, opts)
+
+		values := make(map[string]string, len(families))
+		expvar.Do(func(kv expvar.KeyValue) {
+			values[kv.Key] = kv.Value.String()
+		})
+		for key, v := range families {
+			b, err := json.Marshal(v)
+			if err != nil {
+				// Cannot happen as values only consist of maps, strings
+				// and finite numbers.
+				continue
+			}
+			values[key] = string(b)
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, "{\n")
+		for i, key := range keys {
+			if i > 0 {
+				fmt.Fprintf(w, ",\n")
+			}
+			fmt.Fprintf(w, "%q: %s", key, values[key])
+		}
+		fmt.Fprintf(w, "\n}\n")
+	})
+}
+
+// familyValue converts a metric family into its expvar JSON representation.
+func familyValue(mf *dto.MetricFamily) any {
+	var result any
+metrics:
+	for _, m := range mf.GetMetric() {
+		v := metricValue(mf.GetType(), m)
+		lps := m.GetLabel()
+		if len(lps) == 0 {
+			// A family without labels has only one metric.
+			result = v
+			continue
+		}
+
+		parent, ok := result.(map[string]any)
+		if !ok {
+			if result != nil {
+				// Inconsistent label dimensions, ignore.
+				continue
+			}
+			parent = map[string]any{}
+			result = parent
+		}
+		for _, lp := range lps[:len(lps)-1] {
+			child, ok := parent[lp.GetValue()].(map[string]any)
+			if !ok {
+				if parent[lp.GetValue()] != nil {
+					continue metrics
+				}
+				child = map[string]any{}
+				parent[lp.GetValue()] = child
+			}
+			parent = child
+		}
+		parent[lps[len(lps)-1].GetValue()] = v
+	}
+	return result
+}
+
+func metricValue(t dto.MetricType, m *dto.Metric) any {
+	switch t {
+	case dto.MetricType_COUNTER:
+		return number(m.GetCounter().GetValue())
+	case dto.MetricType_GAUGE:
+		return number(m.GetGauge().GetValue())
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		quantiles := make(map[string]any, len(s.GetQuantile()))
+		for _, q := range s.GetQuantile() {
+			quantiles[formatFloat(q.GetQuantile())] = number(q.GetValue())
+		}
+		return map[string]any{
+			"count":     s.GetSampleCount(),
+			"sum":       number(s.GetSampleSum()),
+			"quantiles": quantiles,
+		}
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		h := m.GetHistogram()
+		count := any(h.GetSampleCount())
+		if h.SampleCountFloat != nil {
+			count = number(h.GetSampleCountFloat())
+		}
+		result := map[string]any{
+			"count": count,
+			"sum":   number(h.GetSampleSum()),
+		}
+		if len(h.GetBucket()) > 0 {
+			buckets := make(map[string]any, len(h.GetBucket())+1)
+			for _, b := range h.GetBucket() {
+				if b.CumulativeCountFloat != nil {
+					buckets[formatFloat(b.GetUpperBound())] = number(b.GetCumulativeCountFloat())
+				} else {
+					buckets[formatFloat(b.GetUpperBound())] = b.GetCumulativeCount()
+				}
+			}
+			if _, ok := buckets["+Inf"]; !ok {
+				buckets["+Inf"] = count
+			}
+			result["buckets"] = buckets
+		}
+		return result
+	default:
+		return number(m.GetUntyped().GetValue())
+	}
+}
+
+// number returns v if it can be represented in JSON, and its string
+// representation otherwise.
+func number(v float64) any {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return formatFloat(v)
+	}
+	return v
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, +1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promexpvar
+
+import (
+	"encoding/json"
+	"expvar"
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newTestRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Total requests.",
+	}, []string{"method", "code"})
+	requests.WithLabelValues("get", "200").Add(3)
+	requests.WithLabelValues("post", "200").Inc()
+	requests.WithLabelValues("get", "500").Inc()
+
+	temperature := prometheus.NewGauge(prometheus.GaugeOpts{Name: "temperature", Help: "Temperature."})
+	temperature.Set(math.Inf(-1))
+
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "latency_seconds",
+		Help:    "Latency.",
+		Buckets: []float64{0.1, 1},
+	})
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(5)
+
+	reg.MustRegister(requests, temperature, latency)
+	return reg
+}
+
+func TestValue(t *testing.T) {
+	v, err := Value(newTestRegistry(), Opts{Prefix: "app_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Round-trip through JSON to compare with what consumers see.
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"app_requests_total": map[string]any{
+			"200": map[string]any{"get": 3.0, "post": 1.0},
+			"500": map[string]any{"get": 1.0},
+		},
+		"app_temperature": "-Inf",
+		"app_latency_seconds": map[string]any{
+			"count":   3.0,
+			"sum":     5.55,
+			"buckets": map[string]any{"0.1": 1.0, "1": 2.0, "+Inf": 3.0},
+		},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected value (-want +got):\n%s", diff)
+	}
+}
+
+func TestPublishAndHandler(t *testing.T) {
+	reg := newTestRegistry()
+	Publish(reg, Opts{Families: []string{"requests_total"}, Prefix: "test_"})
+
+	if got := expvar.Get("test_requests_total").String(); got != `{"200":{"get":3,"post":1},"500":{"get":1}}` {
+		t.Errorf("unexpected published value %s", got)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler(reg, Opts{Families: []string{"temperature"}}).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/vars", nil))
+
+	var got map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	for _, key := range []string{"cmdline", "memstats", "test_requests_total", "temperature"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("missing key %q", key)
+		}
+	}
+	if _, ok := got["latency_seconds"]; ok {
+		t.Error("unselected family latency_seconds exported")
+	}
+}
@@ -41,6 +41,7 @@ import (
 	"sync"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 
 	"github.com/prometheus/client_golang/internal/github.com/golang/gddo/httputil"
@@ -105,13 +106,24 @@ func Handler() http.Handler {
 // Gatherers, with non-default HandlerOpts, and/or with custom (or no)
 // instrumentation. Use the InstrumentMetricHandler function to apply the same
 // kind of instrumentation as it is used by the Handler function.
+//
+// If EnableCollectorSelection is set in the HandlerOpts and the Gatherer is a
+// prometheus.SelectiveGatherer (like a prometheus.Registry), scrapers can
+// select the named Collectors to collect with the "collect[]" URL query
+// parameter, and exclude named Collectors with the "exclude[]" URL query
+// parameter, e.g. "/metrics?collect[]=cheap&collect[]=fast". Collectors not
+// selected are not collected at all. Requests naming unknown Collectors are
+// responded to with 400 Bad Request. See prometheus.Registry.RegisterNamed for
+// how to register named Collectors.
 func HandlerFor(reg prometheus.Gatherer, opts HandlerOpts) http.Handler {
 	return HandlerForTransactional(prometheus.ToTransactionalGatherer(reg), opts)
 }
 
 // HandlerForTransactional is like HandlerFor, but it uses transactional gather, which
 // can safely change in-place returned *dto.MetricFamily before call to `Gather` and after
-// call to `done` of that `Gather`.
+// call to `done` of that `Gather`. Collector selection (see HandlerFor) is
+// supported if enabled and if the TransactionalGatherer is a
+// prometheus.TransactionalSelectiveGatherer.
 func HandlerForTransactional(reg prometheus.TransactionalGatherer, opts HandlerOpts) http.Handler {
 	var (
 		inFlightSem chan struct{}
@@ -168,8 +180,12 @@ func HandlerForTransactional(reg prometheus.TransactionalGatherer, opts HandlerO
 				return
 			}
 		}
-		mfs, done, err := reg.Gather()
+		mfs, done, err := gather(reg, req, opts.EnableCollectorSelection)
 		defer done()
+		if errors.Is(err, prometheus.ErrUnknownCollector) {
+			http.Error(rsp, err.Error(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			if opts.ErrorLog != nil {
 				opts.ErrorLog.Println("error gathering metrics:", err)
@@ -267,6 +283,23 @@ func HandlerForTransactional(reg prometheus.TransactionalGatherer, opts HandlerO
 	))
 }
 
+// gather gathers from the provided TransactionalGatherer. If selection is
+// enabled and it is a prometheus.TransactionalSelectiveGatherer, the Collectors
+// to gather from are selected by the "collect[]" and "exclude[]" URL query
+// parameters of the provided request.
+func gather(reg prometheus.TransactionalGatherer, req *http.Request, selection bool) (_ []*dto.MetricFamily, done func(), err error) {
+	sg, ok := reg.(prometheus.TransactionalSelectiveGatherer)
+	if !ok || !selection {
+		return reg.Gather()
+	}
+	query := req.URL.Query()
+	include, exclude := query["collect[]"], query["exclude[]"]
+	if len(include) == 0 && len(exclude) == 0 {
+		return reg.Gather()
+	}
+	return sg.GatherSelected(include, exclude)
+}
+
 // InstrumentMetricHandler is usually used with an http.Handler returned by the
 // HandlerFor function. It instruments the provided http.Handler with two
 // metrics: A counter vector "promhttp_metric_handler_requests_total" to count
@@ -443,6 +476,11 @@ type HandlerOpts struct {
 	// NOTE: This feature is experimental and not covered by OpenMetrics or Prometheus
 	// exposition format.
 	ProcessStartTime time.Time
+	// If true, scrapers can select the named Collectors to collect with the
+	// "collect[]" and "exclude[]" URL query parameters, see HandlerFor. If
+	// false (the default), those parameters are ignored, and all Collectors
+	// are collected.
+	EnableCollectorSelection bool
 }
 
 // httpError removes any content-encoding header and then calls http.Error with
@@ -367,6 +367,78 @@ func TestHandlerTimeout(t *testing.T) {
 	close(c.Block) // To not leak a goroutine.
 }
 
+// countingCollector counts how often it has been collected.
+type countingCollector struct {
+	prometheus.Gauge
+	collected int
+}
+
+func (c *countingCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collected++
+	c.Gauge.Collect(ch)
+}
+
+func TestHandlerCollectorSelection(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cheap := &countingCollector{Gauge: prometheus.NewGauge(prometheus.GaugeOpts{Name: "cheap", Help: "cheap"})}
+	expensive := &countingCollector{Gauge: prometheus.NewGauge(prometheus.GaugeOpts{Name: "expensive", Help: "expensive"})}
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "unnamed", Help: "unnamed"}))
+	if err := reg.RegisterNamed("cheap", cheap); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterNamed("expensive", expensive); err != nil {
+		t.Fatal(err)
+	}
+	handler := HandlerFor(reg, HandlerOpts{EnableCollectorSelection: true})
+
+	for _, tc := range []struct {
+		query             string
+		disabled          bool
+		code              int
+		families          []string
+		expensiveCollects int
+	}{
+		{query: "", code: http.StatusOK, families: []string{"cheap", "expensive", "unnamed"}, expensiveCollects: 1},
+		{query: "?collect[]=cheap", disabled: true, code: http.StatusOK, families: []string{"cheap", "expensive", "unnamed"}, expensiveCollects: 1},
+		{query: "?collect[]=unknown", disabled: true, code: http.StatusOK, families: []string{"cheap", "expensive", "unnamed"}, expensiveCollects: 1},
+		{query: "?collect[]=cheap", code: http.StatusOK, families: []string{"cheap", "unnamed"}},
+		{query: "?exclude[]=cheap", code: http.StatusOK, families: []string{"expensive", "unnamed"}, expensiveCollects: 1},
+		{query: "?collect[]=cheap&collect[]=expensive&exclude[]=expensive", code: http.StatusOK, families: []string{"cheap", "unnamed"}},
+		{query: "?collect[]=unknown", code: http.StatusBadRequest},
+	} {
+		t.Run(fmt.Sprintf("%s disabled=%t", tc.query, tc.disabled), func(t *testing.T) {
+			expensive.collected = 0
+			w := httptest.NewRecorder()
+			request, _ := http.NewRequest(http.MethodGet, "/"+tc.query, nil)
+			request.Header.Add(acceptHeader, acceptTextPlain)
+			if tc.disabled {
+				HandlerFor(reg, HandlerOpts{}).ServeHTTP(w, request)
+			} else {
+				handler.ServeHTTP(w, request)
+			}
+
+			if got, want := w.Code, tc.code; got != want {
+				t.Fatalf("got HTTP status code %d, want %d", got, want)
+			}
+			if got, want := expensive.collected, tc.expensiveCollects; got != want {
+				t.Errorf("expensive collector collected %d times, want %d", got, want)
+			}
+			if tc.code != http.StatusOK {
+				return
+			}
+			var got []string
+			for _, line := range strings.Split(w.Body.String(), "\n") {
+				if strings.HasPrefix(line, "# TYPE ") {
+					got = append(got, strings.Fields(line)[2])
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.families) {
+				t.Errorf("got families %v, want %v", got, tc.families)
+			}
+		})
+	}
+}
+
 func TestInstrumentMetricHandlerWithCompression(t *testing.T) {
 	reg := prometheus.NewRegistry()
 	mReg := &mockTransactionGatherer{g: reg}
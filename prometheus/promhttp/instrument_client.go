@@ -59,7 +59,7 @@ func InstrumentRoundTripperInFlight(gauge prometheus.Gauge, next http.RoundTripp
 // If the wrapped RoundTripper panics or returns a non-nil error, the Counter
 // is not incremented.
 //
-// Use with WithExemplarFromContext to instrument the exemplars on the counter of requests.
+// Use with WithExemplarFromContext or WithExemplarFromTraceHeaders to instrument the exemplars on the counter of requests.
 //
 // See the example for ExampleInstrumentRoundTripperDuration for example usage.
 func InstrumentRoundTripperCounter(counter *prometheus.CounterVec, next http.RoundTripper, opts ...Option) RoundTripperFunc {
@@ -75,10 +75,8 @@ func InstrumentRoundTripperCounter(counter *prometheus.CounterVec, next http.Rou
 		resp, err := next.RoundTrip(r)
 		if err == nil {
 			l := labels(code, method, r.Method, resp.StatusCode, rtOpts.extraMethods...)
-			for label, resolve := range rtOpts.extraLabelsFromCtx {
-				l[label] = resolve(resp.Request.Context())
-			}
-			addWithExemplar(counter.With(l), 1, rtOpts.getExemplarFn(r.Context()))
+			rtOpts.addDynamicLabels(resp.Request.Context(), l)
+			addWithExemplar(counter.With(l), 1, rtOpts.exemplar(r))
 		}
 		return resp, err
 	}
@@ -101,7 +99,7 @@ func InstrumentRoundTripperCounter(counter *prometheus.CounterVec, next http.Rou
 // If the wrapped RoundTripper panics or returns a non-nil error, no values are
 // reported.
 //
-// Use with WithExemplarFromContext to instrument the exemplars on the duration histograms.
+// Use with WithExemplarFromContext or WithExemplarFromTraceHeaders to instrument the exemplars on the duration histograms.
 //
 // Note that this method is only guaranteed to never observe negative durations
 // if used with Go1.9+.
@@ -119,10 +117,8 @@ func InstrumentRoundTripperDuration(obs prometheus.ObserverVec, next http.RoundT
 		resp, err := next.RoundTrip(r)
 		if err == nil {
 			l := labels(code, method, r.Method, resp.StatusCode, rtOpts.extraMethods...)
-			for label, resolve := range rtOpts.extraLabelsFromCtx {
-				l[label] = resolve(resp.Request.Context())
-			}
-			observeWithExemplar(obs.With(l), time.Since(start).Seconds(), rtOpts.getExemplarFn(r.Context()))
+			rtOpts.addDynamicLabels(resp.Request.Context(), l)
+			observeWithExemplar(obs.With(l), time.Since(start).Seconds(), rtOpts.exemplar(r))
 		}
 		return resp, err
 	}
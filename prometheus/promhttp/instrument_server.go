@@ -92,26 +92,24 @@ func InstrumentHandlerDuration(obs prometheus.ObserverVec, next http.Handler, op
 
 	if code {
 		return func(w http.ResponseWriter, r *http.Request) {
+			r = hOpts.withLabelCarrier(r)
 			now := time.Now()
 			d := newDelegator(w, nil)
 			next.ServeHTTP(d, r)
 
 			l := labels(code, method, r.Method, d.Status(), hOpts.extraMethods...)
-			for label, resolve := range hOpts.extraLabelsFromCtx {
-				l[label] = resolve(r.Context())
-			}
-			observeWithExemplar(obs.With(l), time.Since(now).Seconds(), hOpts.getExemplarFn(r.Context()))
+			hOpts.addDynamicLabels(r.Context(), l)
+			observeWithExemplar(obs.With(l), time.Since(now).Seconds(), hOpts.exemplar(r))
 		}
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		r = hOpts.withLabelCarrier(r)
 		now := time.Now()
 		next.ServeHTTP(w, r)
 		l := labels(code, method, r.Method, 0, hOpts.extraMethods...)
-		for label, resolve := range hOpts.extraLabelsFromCtx {
-			l[label] = resolve(r.Context())
-		}
-		observeWithExemplar(obs.With(l), time.Since(now).Seconds(), hOpts.getExemplarFn(r.Context()))
+		hOpts.addDynamicLabels(r.Context(), l)
+		observeWithExemplar(obs.With(l), time.Since(now).Seconds(), hOpts.exemplar(r))
 	}
 }
 
@@ -143,25 +141,23 @@ func InstrumentHandlerCounter(counter *prometheus.CounterVec, next http.Handler,
 
 	if code {
 		return func(w http.ResponseWriter, r *http.Request) {
+			r = hOpts.withLabelCarrier(r)
 			d := newDelegator(w, nil)
 			next.ServeHTTP(d, r)
 
 			l := labels(code, method, r.Method, d.Status(), hOpts.extraMethods...)
-			for label, resolve := range hOpts.extraLabelsFromCtx {
-				l[label] = resolve(r.Context())
-			}
-			addWithExemplar(counter.With(l), 1, hOpts.getExemplarFn(r.Context()))
+			hOpts.addDynamicLabels(r.Context(), l)
+			addWithExemplar(counter.With(l), 1, hOpts.exemplar(r))
 		}
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		r = hOpts.withLabelCarrier(r)
 		next.ServeHTTP(w, r)
 
 		l := labels(code, method, r.Method, 0, hOpts.extraMethods...)
-		for label, resolve := range hOpts.extraLabelsFromCtx {
-			l[label] = resolve(r.Context())
-		}
-		addWithExemplar(counter.With(l), 1, hOpts.getExemplarFn(r.Context()))
+		hOpts.addDynamicLabels(r.Context(), l)
+		addWithExemplar(counter.With(l), 1, hOpts.exemplar(r))
 	}
 }
 
@@ -197,13 +193,12 @@ func InstrumentHandlerTimeToWriteHeader(obs prometheus.ObserverVec, next http.Ha
 	code, method := checkLabels(obs.MustCurryWith(hOpts.emptyDynamicLabels()))
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		r = hOpts.withLabelCarrier(r)
 		now := time.Now()
 		d := newDelegator(w, func(status int) {
 			l := labels(code, method, r.Method, status, hOpts.extraMethods...)
-			for label, resolve := range hOpts.extraLabelsFromCtx {
-				l[label] = resolve(r.Context())
-			}
-			observeWithExemplar(obs.With(l), time.Since(now).Seconds(), hOpts.getExemplarFn(r.Context()))
+			hOpts.addDynamicLabels(r.Context(), l)
+			observeWithExemplar(obs.With(l), time.Since(now).Seconds(), hOpts.exemplar(r))
 		})
 		next.ServeHTTP(d, r)
 	}
@@ -239,27 +234,25 @@ func InstrumentHandlerRequestSize(obs prometheus.ObserverVec, next http.Handler,
 
 	if code {
 		return func(w http.ResponseWriter, r *http.Request) {
+			r = hOpts.withLabelCarrier(r)
 			d := newDelegator(w, nil)
 			next.ServeHTTP(d, r)
 			size := computeApproximateRequestSize(r)
 
 			l := labels(code, method, r.Method, d.Status(), hOpts.extraMethods...)
-			for label, resolve := range hOpts.extraLabelsFromCtx {
-				l[label] = resolve(r.Context())
-			}
-			observeWithExemplar(obs.With(l), float64(size), hOpts.getExemplarFn(r.Context()))
+			hOpts.addDynamicLabels(r.Context(), l)
+			observeWithExemplar(obs.With(l), float64(size), hOpts.exemplar(r))
 		}
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		r = hOpts.withLabelCarrier(r)
 		next.ServeHTTP(w, r)
 		size := computeApproximateRequestSize(r)
 
 		l := labels(code, method, r.Method, 0, hOpts.extraMethods...)
-		for label, resolve := range hOpts.extraLabelsFromCtx {
-			l[label] = resolve(r.Context())
-		}
-		observeWithExemplar(obs.With(l), float64(size), hOpts.getExemplarFn(r.Context()))
+		hOpts.addDynamicLabels(r.Context(), l)
+		observeWithExemplar(obs.With(l), float64(size), hOpts.exemplar(r))
 	}
 }
 
@@ -292,14 +285,13 @@ func InstrumentHandlerResponseSize(obs prometheus.ObserverVec, next http.Handler
 	code, method := checkLabels(obs.MustCurryWith(hOpts.emptyDynamicLabels()))
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = hOpts.withLabelCarrier(r)
 		d := newDelegator(w, nil)
 		next.ServeHTTP(d, r)
 
 		l := labels(code, method, r.Method, d.Status(), hOpts.extraMethods...)
-		for label, resolve := range hOpts.extraLabelsFromCtx {
-			l[label] = resolve(r.Context())
-		}
-		observeWithExemplar(obs.With(l), float64(d.Written()), hOpts.getExemplarFn(r.Context()))
+		hOpts.addDynamicLabels(r.Context(), l)
+		observeWithExemplar(obs.With(l), float64(d.Written()), hOpts.exemplar(r))
 	})
 }
 
@@ -21,6 +21,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	dto "github.com/prometheus/client_model/go"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -439,6 +441,102 @@ func TestMiddlewareAPI_WithExemplars(t *testing.T) {
 	assetMetricAndExemplars(t, reg, 5, labelsToLabelPair(exemplar))
 }
 
+func TestMiddlewareAPI_WithMutableLabels(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_requests_total",
+			Help: "A counter for requests to the wrapped handler.",
+		},
+		[]string{"code", "outcome", "tenant"},
+	)
+	histVec := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "response_duration_seconds",
+			Help: "A histogram of request latencies.",
+		},
+		[]string{"outcome"},
+	)
+	reg.MustRegister(counter, histVec)
+
+	outcome := WithMutableLabels(MutableLabel{
+		Name:    "outcome",
+		Default: "none",
+		Constraint: func(v string) string {
+			if v == "none" || v == "accepted" || v == "rejected" {
+				return v
+			}
+			return "other"
+		},
+	})
+	tenant := WithMutableLabels(MutableLabel{Name: "tenant"})
+
+	chain := InstrumentHandlerCounter(counter,
+		InstrumentHandlerDuration(histVec,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !SetMutableLabel(r.Context(), "tenant", r.URL.Query().Get("tenant")) {
+					t.Error("expected label carrier in request context")
+				}
+				if o := r.URL.Query().Get("outcome"); o != "" {
+					SetMutableLabel(r.Context(), "outcome", o)
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}),
+			outcome,
+		),
+		outcome, tenant,
+	)
+
+	for _, target := range []string{
+		"/?tenant=a&outcome=accepted",
+		"/?tenant=a&outcome=accepted",
+		"/?tenant=b&outcome=unexpected",
+		"/?tenant=b",
+	} {
+		chain.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	for _, tc := range []struct {
+		labels   prometheus.Labels
+		expected float64
+	}{
+		{prometheus.Labels{"code": "202", "outcome": "accepted", "tenant": "a"}, 2},
+		{prometheus.Labels{"code": "202", "outcome": "other", "tenant": "b"}, 1},
+		{prometheus.Labels{"code": "202", "outcome": "none", "tenant": "b"}, 1},
+	} {
+		var m dto.Metric
+		if err := counter.With(tc.labels).Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetCounter().GetValue(); got != tc.expected {
+			t.Errorf("got %v for %v, want %v", got, tc.labels, tc.expected)
+		}
+	}
+	if got, want := collectCount(t, histVec), 3; got != want {
+		t.Errorf("got %d histogram series, want %d", got, want)
+	}
+
+	if SetMutableLabel(context.Background(), "tenant", "a") {
+		t.Error("expected SetMutableLabel to fail without label carrier")
+	}
+}
+
+// collectCount returns the number of metrics collected from c.
+func collectCount(t *testing.T, c prometheus.Collector) int {
+	t.Helper()
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	var n int
+	for range ch {
+		n++
+	}
+	return n
+}
+
 func TestInstrumentTimeToFirstWrite(t *testing.T) {
 	var i int
 	dobs := &responseWriterDelegator{
@@ -15,6 +15,8 @@ package promhttp
 
 import (
 	"context"
+	"net/http"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -33,6 +35,8 @@ type options struct {
 	extraMethods       []string
 	getExemplarFn      func(requestCtx context.Context) prometheus.Labels
 	extraLabelsFromCtx map[string]LabelValueFromCtx
+	mutableLabels      []MutableLabel
+	traceHeaderFormats []TraceHeaderFormat
 }
 
 func defaultOptions() *options {
@@ -48,10 +52,61 @@ func (o *options) emptyDynamicLabels() prometheus.Labels {
 	for label := range o.extraLabelsFromCtx {
 		labels[label] = ""
 	}
+	for _, label := range o.mutableLabels {
+		labels[label.Name] = ""
+	}
 
 	return labels
 }
 
+// exemplar returns the exemplar labels for the provided request, or nil if
+// there are none.
+func (o *options) exemplar(r *http.Request) prometheus.Labels {
+	if labels := o.getExemplarFn(r.Context()); labels != nil {
+		return labels
+	}
+	if len(o.traceHeaderFormats) == 0 {
+		return nil
+	}
+	return exemplarFromTraceHeaders(r.Header, o.traceHeaderFormats)
+}
+
+// withLabelCarrier returns the provided request with a labelCarrier in its
+// context, unless mutable labels are not used or the context already contains
+// a carrier (e.g. injected by an outer middleware).
+func (o *options) withLabelCarrier(r *http.Request) *http.Request {
+	if len(o.mutableLabels) == 0 {
+		return r
+	}
+	if _, ok := r.Context().Value(labelCarrierKey{}).(*labelCarrier); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), labelCarrierKey{}, &labelCarrier{}))
+}
+
+// addDynamicLabels sets the values of the labels resolved from the provided
+// request context, i.e. those registered with WithLabelFromCtx and
+// WithMutableLabels.
+func (o *options) addDynamicLabels(ctx context.Context, labels prometheus.Labels) {
+	for label, resolve := range o.extraLabelsFromCtx {
+		labels[label] = resolve(ctx)
+	}
+	if len(o.mutableLabels) == 0 {
+		return
+	}
+	carrier, _ := ctx.Value(labelCarrierKey{}).(*labelCarrier)
+	for _, label := range o.mutableLabels {
+		value, ok := carrier.get(label.Name)
+		if !ok {
+			value = label.Default
+		}
+		if label.Constraint != nil {
+			value = label.Constraint(value)
+		}
+		labels[label.Name] = value
+	}
+}
+
 type optionApplyFunc func(*options)
 
 func (o optionApplyFunc) apply(opt *options) { o(opt) }
@@ -82,3 +137,87 @@ func WithLabelFromCtx(name string, valueFn LabelValueFromCtx) Option {
 		o.extraLabelsFromCtx[name] = valueFn
 	})
 }
+
+// MutableLabel declares a label whose value is set by the wrapped handler via
+// SetMutableLabel while processing the request. See WithMutableLabels.
+type MutableLabel struct {
+	// Name is the label name. The metric vector passed to the middleware
+	// must have a variable label with this name.
+	Name string
+	// Default is the label value used if the wrapped handler has not set
+	// a value.
+	Default string
+	// Constraint, if not nil, normalizes the value set by the wrapped
+	// handler (or the Default). It should be used to bound the
+	// cardinality of the label, e.g. by mapping all unexpected values to
+	// "other".
+	Constraint prometheus.LabelConstraint
+}
+
+// WithMutableLabels declares labels whose values are only known after the
+// wrapped handler has processed the request, e.g. a business outcome or an
+// error class. The middleware injects a label carrier into the request context,
+// and the wrapped handler sets the label values with SetMutableLabel. The
+// middleware reads them when observing. If several middlewares are nested, the
+// outermost one injects the carrier, and all of them share it.
+//
+// In contrast to WithLabelFromCtx, the label values are resolved from the
+// carrier after the wrapped handler returns (or, for
+// InstrumentHandlerTimeToWriteHeader, when the header is written).
+//
+// WithMutableLabels is meant for the InstrumentHandler* middlewares. The
+// InstrumentRoundTripper* middlewares do not inject a carrier, so they only see
+// values set in a carrier already contained in the request context, and the
+// Default otherwise.
+func WithMutableLabels(labels ...MutableLabel) Option {
+	return optionApplyFunc(func(o *options) {
+		o.mutableLabels = append(o.mutableLabels, labels...)
+	})
+}
+
+// SetMutableLabel sets the value of the label with the provided name in the
+// label carrier contained in the provided context, overwriting any value set
+// before. The label must be declared with WithMutableLabels by one of the
+// middlewares wrapping the handler, otherwise the value is ignored.
+// SetMutableLabel returns false if the context does not contain a label carrier,
+// i.e. if the handler is not wrapped by a middleware using WithMutableLabels.
+//
+// It is safe to call SetMutableLabel concurrently.
+func SetMutableLabel(ctx context.Context, name, value string) bool {
+	carrier, ok := ctx.Value(labelCarrierKey{}).(*labelCarrier)
+	if !ok {
+		return false
+	}
+	carrier.set(name, value)
+	return true
+}
+
+type labelCarrierKey struct{}
+
+// labelCarrier holds the values of mutable labels set by a handler. A nil
+// labelCarrier behaves like an empty one.
+type labelCarrier struct {
+	mtx    sync.Mutex
+	values map[string]string
+}
+
+func (c *labelCarrier) set(name, value string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.values == nil {
+		c.values = map[string]string{}
+	}
+	c.values[name] = value
+}
+
+func (c *labelCarrier) get(name string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	value, ok := c.values[name]
+	return value, ok
+}
@@ -126,3 +126,46 @@ func ExampleWithLabelFromCtx() {
 		log.Fatal(err)
 	}
 }
+
+func ExampleWithMutableLabels() {
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_requests_total",
+			Help: "A counter for requests to the wrapped handler.",
+		},
+		[]string{"code", "outcome"},
+	)
+
+	// The "outcome" label is only known once the request has been
+	// processed. Bound its cardinality by mapping unexpected values to
+	// "other".
+	opts := WithMutableLabels(MutableLabel{
+		Name:    "outcome",
+		Default: "unknown",
+		Constraint: func(v string) string {
+			switch v {
+			case "unknown", "accepted", "rejected":
+				return v
+			default:
+				return "other"
+			}
+		},
+	})
+
+	pushHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength == 0 {
+			SetMutableLabel(r.Context(), "outcome", "rejected")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		SetMutableLabel(r.Context(), "outcome", "accepted")
+		w.Write([]byte("Push"))
+	})
+
+	http.Handle("/metrics", Handler())
+	http.Handle("/push", InstrumentHandlerCounter(counter, pushHandler, opts))
+
+	if err := http.ListenAndServe(":3000", nil); err != nil {
+		log.Fatal(err)
+	}
+}
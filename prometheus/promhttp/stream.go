@@ -0,0 +1,387 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefStreamInterval is the default interval of a stream handler.
+	DefStreamInterval = time.Second
+	// DefStreamMaxSeries is the default maximum number of series per
+	// client of a stream handler.
+	DefStreamMaxSeries = 1000
+)
+
+// StreamOpts specifies options for StreamHandlerFor. The zero value of
+// StreamOpts is a reasonable default.
+type StreamOpts struct {
+	// ErrorLog specifies an optional Logger for gathering and writing
+	// errors. If nil, errors are not logged at all.
+	ErrorLog Logger
+	// Interval is the interval in which metrics are gathered and changes
+	// are sent to each client. If zero or negative, DefStreamInterval is
+	// used. Clients may request a longer (but not a shorter) interval with
+	// the "interval" query parameter, e.g. "interval=5s".
+	Interval time.Duration
+	// The number of concurrent streams is limited to MaxClients.
+	// Additional requests are responded to with 503 Service Unavailable.
+	// If MaxClients is 0 or negative, no limit is applied.
+	MaxClients int
+	// MaxSeries limits the number of series tracked for each client. If
+	// more series match the patterns requested by a client, only the first
+	// MaxSeries series in lexicographical order of name and labels are
+	// tracked, and each event is flagged as truncated. If zero,
+	// DefStreamMaxSeries is used. If negative, no limit is applied.
+	MaxSeries int
+}
+
+// StreamHandlerFor returns an http.Handler that streams changes of the metrics
+// gathered from g as Server-Sent Events. It is meant for local debugging UIs
+// that want to watch metrics change in real time without running a Prometheus
+// server. It is not a replacement for scraping.
+//
+// Clients select metric families with one or more "match[]" query parameters,
+// each of which is a metric family name or a pattern as understood by
+// path.Match, e.g. "http_*". Without any "match[]" parameter, all families are
+// streamed.
+//
+// Every interval, the handler gathers from g and sends an "update" event to
+// each client with the series that changed since the previous event, or a
+// comment line as heartbeat if nothing changed. The first event contains all
+// matching series. The data of an event is a JSON object like the following:
+//
+//	{
+//	  "timestamp": 1700000000000,
+//	  "series": [
+//	    {"name": "jobs_total", "labels": {"queue": "a"}, "type": "counter", "value": 42},
+//	    {"name": "latency_seconds", "type": "histogram", "count": 12, "sum": 1.5, "count_delta": 2, "sum_delta": 0.25}
+//	  ],
+//	  "removed": [{"name": "jobs_total", "labels": {"queue": "b"}}],
+//	  "truncated": false
+//	}
+//
+// Counters, gauges, and untyped metrics have a "value". Histograms and
+// summaries have a "count" and a "sum" together with their deltas since the
+// previous event. NaN and infinite values are encoded as the strings "NaN",
+// "+Inf", and "-Inf". Gathering errors are sent as "error" events with the
+// error message as data, but do not end the stream.
+//
+// The stream ends once the request context is canceled, i.e. when the client
+// disconnects. Note that http.Server.Shutdown does not cancel the contexts of
+// active requests. To end all streams upon shutdown, set the BaseContext of the
+// http.Server to a context that is canceled before calling Shutdown.
+func StreamHandlerFor(g prometheus.Gatherer, opts StreamOpts) http.Handler {
+	if opts.Interval <= 0 {
+		opts.Interval = DefStreamInterval
+	}
+	if opts.MaxSeries == 0 {
+		opts.MaxSeries = DefStreamMaxSeries
+	}
+	var clientSem chan struct{}
+	if opts.MaxClients > 0 {
+		clientSem = make(chan struct{}, opts.MaxClients)
+	}
+
+	return http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if clientSem != nil {
+			select {
+			case clientSem <- struct{}{}:
+				defer func() { <-clientSem }()
+			default:
+				http.Error(rsp, fmt.Sprintf(
+					"Limit of concurrent streams reached (%d), try again later.", opts.MaxClients,
+				), http.StatusServiceUnavailable)
+				return
+			}
+		}
+
+		patterns := req.URL.Query()["match[]"]
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				http.Error(rsp, fmt.Sprintf("Invalid match[] pattern %q: %s", p, err), http.StatusBadRequest)
+				return
+			}
+		}
+		interval := opts.Interval
+		if s := req.URL.Query().Get("interval"); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				http.Error(rsp, fmt.Sprintf("Invalid interval %q: %s", s, err), http.StatusBadRequest)
+				return
+			}
+			if d > interval {
+				interval = d
+			}
+		}
+
+		rc := http.NewResponseController(rsp)
+		// Streams are long-lived, so any write deadline of the server
+		// would end them prematurely. Not all ResponseWriters support
+		// this, so ignore the error.
+		_ = rc.SetWriteDeadline(time.Time{})
+		header := rsp.Header()
+		header.Set(contentTypeHeader, "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		rsp.WriteHeader(http.StatusOK)
+
+		s := &stream{
+			gatherer:  g,
+			patterns:  patterns,
+			maxSeries: opts.MaxSeries,
+			last:      map[string]streamSeries{},
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			// Metrics gathered despite an error are still sent.
+			event, gatherErr := s.next()
+			_, err := rsp.Write(event)
+			if err == nil && gatherErr != nil {
+				if opts.ErrorLog != nil {
+					opts.ErrorLog.Println("error gathering metrics for stream:", gatherErr)
+				}
+				_, err = fmt.Fprintf(rsp, "event: error\ndata: %s\n\n", strings.ReplaceAll(gatherErr.Error(), "\n", "\ndata: "))
+			}
+			if err == nil {
+				err = rc.Flush()
+			}
+			if err != nil {
+				// The client is gone or the ResponseWriter does not
+				// support streaming.
+				if opts.ErrorLog != nil && req.Context().Err() == nil {
+					opts.ErrorLog.Println("error writing stream:", err)
+				}
+				return
+			}
+
+			select {
+			case <-req.Context().Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// stream is the state of a single client's stream.
+type stream struct {
+	gatherer  prometheus.Gatherer
+	patterns  []string
+	maxSeries int
+	last      map[string]streamSeries
+	// initialSent is true once the first event has been created. It is
+	// tracked separately from last as the first event might contain no
+	// series at all.
+	initialSent bool
+}
+
+// streamSeries is a series as sent in an event.
+type streamSeries struct {
+	Name       string            `json:"name"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Type       string            `json:"type,omitempty"`
+	Value      *streamFloat      `json:"value,omitempty"`
+	Count      *uint64           `json:"count,omitempty"`
+	Sum        *streamFloat      `json:"sum,omitempty"`
+	CountDelta *int64            `json:"count_delta,omitempty"`
+	SumDelta   *streamFloat      `json:"sum_delta,omitempty"`
+}
+
+// streamEvent is the data of an update event.
+type streamEvent struct {
+	Timestamp int64          `json:"timestamp"`
+	Series    []streamSeries `json:"series"`
+	Removed   []streamSeries `json:"removed,omitempty"`
+	Truncated bool           `json:"truncated"`
+}
+
+// streamFloat is a float64 that encodes NaN and infinite values as strings.
+type streamFloat float64
+
+func (f streamFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	switch {
+	case math.IsNaN(v):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(v, +1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Inf"`), nil
+	}
+	return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+}
+
+// equal returns true if f and o are the same, including both being NaN.
+func (f *streamFloat) equal(o *streamFloat) bool {
+	if f == nil || o == nil {
+		return f == o
+	}
+	return math.Float64bits(float64(*f)) == math.Float64bits(float64(*o)) || *f == *o
+}
+
+// next gathers metrics and returns the next event in wire format, or a
+// heartbeat comment if nothing has changed.
+func (s *stream) next() ([]byte, error) {
+	mfs, err := s.gatherer.Gather()
+	if err != nil && len(mfs) == 0 {
+		return nil, err
+	}
+
+	current := map[string]streamSeries{}
+	var keys []string
+	for _, mf := range mfs {
+		if !s.matches(mf.GetName()) {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			series := makeStreamSeries(mf, m)
+			key := seriesKey(series)
+			current[key] = series
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	event := streamEvent{Timestamp: time.Now().UnixMilli(), Series: []streamSeries{}}
+	if s.maxSeries > 0 && len(keys) > s.maxSeries {
+		for _, key := range keys[s.maxSeries:] {
+			delete(current, key)
+		}
+		keys = keys[:s.maxSeries]
+		event.Truncated = true
+	}
+	for _, key := range keys {
+		series := current[key]
+		last, ok := s.last[key]
+		if ok && series.Value.equal(last.Value) && series.Sum.equal(last.Sum) &&
+			(series.Count == nil) == (last.Count == nil) && (series.Count == nil || *series.Count == *last.Count) {
+			continue
+		}
+		if ok && series.Count != nil && last.Count != nil {
+			countDelta := int64(*series.Count - *last.Count)
+			sumDelta := *series.Sum - *last.Sum
+			series.CountDelta, series.SumDelta = &countDelta, &sumDelta
+		}
+		event.Series = append(event.Series, series)
+	}
+	var removed []string
+	for key := range s.last {
+		if _, ok := current[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		last := s.last[key]
+		event.Removed = append(event.Removed, streamSeries{Name: last.Name, Labels: last.Labels})
+	}
+	initial := !s.initialSent
+	s.last, s.initialSent = current, true
+
+	if !initial && len(event.Series) == 0 && len(event.Removed) == 0 {
+		return []byte(": heartbeat\n\n"), err
+	}
+	data, jsonErr := json.Marshal(event)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+	return []byte("event: update\ndata: " + string(data) + "\n\n"), err
+}
+
+// matches returns true if the family with the provided name was requested.
+func (s *stream) matches(name string) bool {
+	if len(s.patterns) == 0 {
+		return true
+	}
+	for _, p := range s.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func makeStreamSeries(mf *dto.MetricFamily, m *dto.Metric) streamSeries {
+	series := streamSeries{
+		Name: mf.GetName(),
+		Type: strings.ToLower(mf.GetType().String()),
+	}
+	if len(m.GetLabel()) > 0 {
+		series.Labels = make(map[string]string, len(m.GetLabel()))
+		for _, lp := range m.GetLabel() {
+			series.Labels[lp.GetName()] = lp.GetValue()
+		}
+	}
+	setCountAndSum := func(count uint64, sum float64) {
+		f := streamFloat(sum)
+		series.Count, series.Sum = &count, &f
+	}
+	var value float64
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER:
+		value = m.GetCounter().GetValue()
+	case dto.MetricType_GAUGE:
+		value = m.GetGauge().GetValue()
+	case dto.MetricType_SUMMARY:
+		setCountAndSum(m.GetSummary().GetSampleCount(), m.GetSummary().GetSampleSum())
+		return series
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		h := m.GetHistogram()
+		count := h.GetSampleCount()
+		if h.SampleCountFloat != nil {
+			count = uint64(h.GetSampleCountFloat())
+		}
+		setCountAndSum(count, h.GetSampleSum())
+		return series
+	default:
+		value = m.GetUntyped().GetValue()
+	}
+	f := streamFloat(value)
+	series.Value = &f
+	return series
+}
+
+// seriesKey returns a key identifying the series, which sorts by name first
+// and labels second.
+func seriesKey(s streamSeries) string {
+	names := make([]string, 0, len(s.Labels))
+	for name := range s.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(s.Name)
+	for _, name := range names {
+		b.WriteByte(model.SeparatorByte)
+		b.WriteString(name)
+		b.WriteByte(model.SeparatorByte)
+		b.WriteString(s.Labels[name])
+	}
+	return b.String()
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// readStreamEvent reads events from r until it finds an update event and
+// returns its data.
+func readStreamEvent(t *testing.T, r *bufio.Reader) map[string]interface{} {
+	t.Helper()
+	var isUpdate bool
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "event: update":
+			isUpdate = true
+		case isUpdate && strings.HasPrefix(line, "data: "):
+			var event map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				t.Fatal(err)
+			}
+			return event
+		}
+	}
+}
+
+func TestStreamHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	queue := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "queue_length", Help: "help"}, []string{"queue"})
+	jobs := prometheus.NewCounter(prometheus.CounterOpts{Name: "jobs_total", Help: "help"})
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Help: "help"})
+	reg.MustRegister(queue, jobs, latency)
+	queue.WithLabelValues("a").Set(1)
+	queue.WithLabelValues("b").Set(2)
+	latency.Observe(1)
+
+	// Changes are made while holding mtx, so that they show up in the same
+	// event.
+	var mtx sync.Mutex
+	g := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return reg.Gather()
+	})
+	server := httptest.NewServer(StreamHandlerFor(g, StreamOpts{Interval: 10 * time.Millisecond, MaxClients: 1}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?match[]=queue_*&match[]=latency_seconds", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get(contentTypeHeader); got != "text/event-stream" {
+		t.Errorf("got content type %q", got)
+	}
+	r := bufio.NewReader(resp.Body)
+
+	// The first event contains all matching series.
+	event := readStreamEvent(t, r)
+	if got := len(event["series"].([]interface{})); got != 3 {
+		t.Errorf("got %d series in first event, want 3: %v", got, event)
+	}
+
+	// A second client is rejected.
+	if resp, err := http.Get(server.URL); err != nil {
+		t.Error(err)
+	} else {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("got status %d for second client, want 503", resp.StatusCode)
+		}
+	}
+
+	// Only changed series are sent.
+	mtx.Lock()
+	queue.WithLabelValues("a").Set(5)
+	queue.DeleteLabelValues("b")
+	latency.Observe(0.5)
+	jobs.Inc()
+	mtx.Unlock()
+	event = readStreamEvent(t, r)
+	series := event["series"].([]interface{})
+	if len(series) != 2 {
+		t.Fatalf("got series %v, want 2", series)
+	}
+	his := series[0].(map[string]interface{})
+	if his["name"] != "latency_seconds" || his["count_delta"] != 1. || his["sum_delta"] != 0.5 {
+		t.Errorf("unexpected histogram series %v", his)
+	}
+	gauge := series[1].(map[string]interface{})
+	if gauge["name"] != "queue_length" || gauge["value"] != 5. {
+		t.Errorf("unexpected gauge series %v", gauge)
+	}
+	removed := event["removed"].([]interface{})
+	if len(removed) != 1 || removed[0].(map[string]interface{})["labels"].(map[string]interface{})["queue"] != "b" {
+		t.Errorf("unexpected removed series %v", removed)
+	}
+
+	// Canceling the request ends the stream and frees the client slot.
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(server.URL + "?match[]=[")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusBadRequest {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got status %d after cancellation, want 400 for an invalid pattern", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStreamHandlerHeartbeatAfterEmptyStart(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	server := httptest.NewServer(StreamHandlerFor(reg, StreamOpts{Interval: 10 * time.Millisecond}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+
+	// The first event is sent even without any series.
+	event := readStreamEvent(t, r)
+	if got := len(event["series"].([]interface{})); got != 0 {
+		t.Errorf("got %d series in first event, want 0: %v", got, event)
+	}
+	// Afterwards, heartbeats keep the idle connection alive.
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == ": heartbeat\n" {
+			break
+		}
+		if line == "event: update\n" {
+			t.Fatal("got update event instead of heartbeat")
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TraceHeaderFormat is a format of HTTP headers propagating the trace context
+// of a request, see WithExemplarFromTraceHeaders.
+type TraceHeaderFormat int
+
+// The supported trace header formats.
+const (
+	// TraceContextFormat is the "traceparent" header as specified by the
+	// W3C Trace Context recommendation: https://www.w3.org/TR/trace-context/
+	TraceContextFormat TraceHeaderFormat = iota
+	// B3Format is the single "b3" header or, if absent, the "X-B3-TraceId",
+	// "X-B3-SpanId", "X-B3-Sampled", and "X-B3-Flags" headers as specified
+	// by https://github.com/openzipkin/b3-propagation
+	B3Format
+)
+
+// WithExemplarFromTraceHeaders adds exemplars with the labels "trace_id" and
+// "span_id" taken from the trace headers of the request, in the provided
+// formats. This allows to add exemplars without a tracing library, e.g. if a
+// proxy in front of the instrumented server starts the traces. If no format is
+// provided, TraceContextFormat is used. If several formats are provided, the
+// first one with valid headers is used.
+//
+// Exemplars are only added if the trace is sampled according to the headers.
+// Invalid headers are ignored. If an exemplar is also provided by the function
+// passed to WithExemplarFromContext, that one takes precedence.
+//
+// For the InstrumentHandler* middlewares, the headers of the incoming request
+// are used. For the InstrumentRoundTripper* middlewares, the headers of the
+// outgoing request are used, so they have to be set before the request is
+// passed to the RoundTripper, e.g. by forwarding the headers of the incoming
+// request.
+func WithExemplarFromTraceHeaders(formats ...TraceHeaderFormat) Option {
+	if len(formats) == 0 {
+		formats = []TraceHeaderFormat{TraceContextFormat}
+	}
+	return optionApplyFunc(func(o *options) {
+		o.traceHeaderFormats = formats
+	})
+}
+
+// exemplarFromTraceHeaders returns the exemplar labels of the first of the
+// provided formats with valid headers in h, or nil if there are none or the
+// trace is not sampled.
+func exemplarFromTraceHeaders(h http.Header, formats []TraceHeaderFormat) prometheus.Labels {
+	for _, f := range formats {
+		var traceID, spanID string
+		var sampled, ok bool
+		switch f {
+		case TraceContextFormat:
+			traceID, spanID, sampled, ok = parseTraceparent(h.Get("traceparent"))
+		case B3Format:
+			traceID, spanID, sampled, ok = parseB3(h)
+		}
+		if !ok {
+			continue
+		}
+		if !sampled {
+			return nil
+		}
+		return prometheus.Labels{"trace_id": traceID, "span_id": spanID}
+	}
+	return nil
+}
+
+// parseTraceparent parses a traceparent header of the form
+// "{version}-{trace-id}-{parent-id}-{trace-flags}". ok is false if the header
+// is absent or invalid.
+func parseTraceparent(s string) (traceID, spanID string, sampled, ok bool) {
+	// Version 00 has exactly 55 characters. Future versions may append
+	// fields, which have to be separated by a dash.
+	if len(s) < 55 || (len(s) > 55 && s[55] != '-') ||
+		s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return "", "", false, false
+	}
+	version, traceID, spanID, flags := s[0:2], s[3:35], s[36:52], s[53:55]
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(s) != 55) ||
+		!isTraceID(traceID) || !isSpanID(spanID) || !isLowerHex(flags) {
+		return "", "", false, false
+	}
+	// The sampled flag is the least significant bit of the trace flags.
+	return traceID, spanID, strings.IndexByte("13579bdf", flags[1]) >= 0, true
+}
+
+// parseB3 parses the single b3 header of the form
+// "{TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}", with the last two fields
+// being optional, or, if absent, the multiple X-B3-* headers. ok is false if the
+// headers are absent, invalid, or contain no trace and span ID.
+func parseB3(h http.Header) (traceID, spanID string, sampled, ok bool) {
+	if single := h.Get("b3"); single != "" {
+		parts := strings.Split(single, "-")
+		if len(parts) < 2 || len(parts) > 4 {
+			// Also covers a sampling state only, e.g. "b3: 1".
+			return "", "", false, false
+		}
+		traceID, spanID = parts[0], parts[1]
+		if len(parts) >= 3 {
+			switch parts[2] {
+			case "1", "d":
+				sampled = true
+			case "0":
+			default:
+				return "", "", false, false
+			}
+		}
+		if len(parts) == 4 && !isSpanID(parts[3]) {
+			return "", "", false, false
+		}
+	} else {
+		traceID, spanID = h.Get("X-B3-TraceId"), h.Get("X-B3-SpanId")
+		switch h.Get("X-B3-Sampled") {
+		case "1", "true":
+			sampled = true
+		case "", "0", "false":
+		default:
+			return "", "", false, false
+		}
+		// The debug flag implies sampling.
+		if h.Get("X-B3-Flags") == "1" {
+			sampled = true
+		}
+	}
+	if !isB3TraceID(traceID) || !isSpanID(spanID) {
+		return "", "", false, false
+	}
+	return traceID, spanID, sampled, true
+}
+
+// isTraceID returns whether s is a valid 128-bit trace ID, i.e. 32 lowercase
+// hex characters that are not all zero.
+func isTraceID(s string) bool {
+	return len(s) == 32 && isLowerHex(s) && !isAllZeros(s)
+}
+
+// isB3TraceID returns whether s is a valid 64-bit or 128-bit B3 trace ID.
+func isB3TraceID(s string) bool {
+	return (len(s) == 16 || len(s) == 32) && isLowerHex(s) && !isAllZeros(s)
+}
+
+// isSpanID returns whether s is a valid 64-bit span ID, i.e. 16 lowercase hex
+// characters that are not all zero.
+func isSpanID(s string) bool {
+	return len(s) == 16 && isLowerHex(s) && !isAllZeros(s)
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAllZeros(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
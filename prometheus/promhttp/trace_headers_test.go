@@ -0,0 +1,292 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func TestExemplarFromTraceHeaders(t *testing.T) {
+	sampled := prometheus.Labels{"trace_id": testTraceID, "span_id": testSpanID}
+	b3Sampled64 := prometheus.Labels{"trace_id": testTraceID[16:], "span_id": testSpanID}
+
+	for _, tc := range []struct {
+		name    string
+		header  map[string]string
+		formats []TraceHeaderFormat
+		want    prometheus.Labels
+	}{
+		{name: "no headers", formats: []TraceHeaderFormat{TraceContextFormat, B3Format}},
+		{
+			name:   "traceparent sampled",
+			header: map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID + "-01"},
+			want:   sampled,
+		},
+		{
+			name:   "traceparent other flags",
+			header: map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID + "-03"},
+			want:   sampled,
+		},
+		{
+			name:   "traceparent not sampled",
+			header: map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID + "-00"},
+		},
+		{
+			name:   "traceparent future version with extra field",
+			header: map[string]string{"traceparent": "01-" + testTraceID + "-" + testSpanID + "-01-extra"},
+			want:   sampled,
+		},
+		{
+			name:   "traceparent version 00 with extra field",
+			header: map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID + "-01-extra"},
+		},
+		{
+			name:   "traceparent invalid version",
+			header: map[string]string{"traceparent": "ff-" + testTraceID + "-" + testSpanID + "-01"},
+		},
+		{
+			name:   "traceparent uppercase",
+			header: map[string]string{"traceparent": "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testSpanID + "-01"},
+		},
+		{
+			name:   "traceparent zero trace ID",
+			header: map[string]string{"traceparent": "00-00000000000000000000000000000000-" + testSpanID + "-01"},
+		},
+		{
+			name:   "traceparent zero span ID",
+			header: map[string]string{"traceparent": "00-" + testTraceID + "-0000000000000000-01"},
+		},
+		{
+			name:   "traceparent too short",
+			header: map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID + "-1"},
+		},
+		{
+			name:    "traceparent ignored without format",
+			header:  map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID + "-01"},
+			formats: []TraceHeaderFormat{B3Format},
+		},
+		{
+			name:    "b3 single sampled",
+			header:  map[string]string{"b3": testTraceID + "-" + testSpanID + "-1"},
+			formats: []TraceHeaderFormat{B3Format},
+			want:    sampled,
+		},
+		{
+			name:    "b3 single debug with parent and 64-bit trace ID",
+			header:  map[string]string{"b3": testTraceID[16:] + "-" + testSpanID + "-d-" + testSpanID},
+			formats: []TraceHeaderFormat{B3Format},
+			want:    b3Sampled64,
+		},
+		{
+			name:    "b3 single deferred",
+			header:  map[string]string{"b3": testTraceID + "-" + testSpanID},
+			formats: []TraceHeaderFormat{B3Format},
+		},
+		{
+			name:    "b3 single sampling state only",
+			header:  map[string]string{"b3": "1"},
+			formats: []TraceHeaderFormat{B3Format},
+		},
+		{
+			name:    "b3 single invalid sampling state",
+			header:  map[string]string{"b3": testTraceID + "-" + testSpanID + "-x"},
+			formats: []TraceHeaderFormat{B3Format},
+		},
+		{
+			name: "b3 multi sampled",
+			header: map[string]string{
+				"X-B3-TraceId": testTraceID,
+				"X-B3-SpanId":  testSpanID,
+				"X-B3-Sampled": "1",
+			},
+			formats: []TraceHeaderFormat{B3Format},
+			want:    sampled,
+		},
+		{
+			name: "b3 multi debug",
+			header: map[string]string{
+				"X-B3-TraceId": testTraceID,
+				"X-B3-SpanId":  testSpanID,
+				"X-B3-Flags":   "1",
+			},
+			formats: []TraceHeaderFormat{B3Format},
+			want:    sampled,
+		},
+		{
+			name: "b3 multi not sampled",
+			header: map[string]string{
+				"X-B3-TraceId": testTraceID,
+				"X-B3-SpanId":  testSpanID,
+				"X-B3-Sampled": "0",
+			},
+			formats: []TraceHeaderFormat{B3Format},
+		},
+		{
+			name: "b3 multi invalid span ID",
+			header: map[string]string{
+				"X-B3-TraceId": testTraceID,
+				"X-B3-SpanId":  "xyz",
+				"X-B3-Sampled": "1",
+			},
+			formats: []TraceHeaderFormat{B3Format},
+		},
+		{
+			name: "fallback to b3 for invalid traceparent",
+			header: map[string]string{
+				"traceparent": "garbage",
+				"b3":          testTraceID + "-" + testSpanID + "-1",
+			},
+			formats: []TraceHeaderFormat{TraceContextFormat, B3Format},
+			want:    sampled,
+		},
+		{
+			name: "no fallback for unsampled traceparent",
+			header: map[string]string{
+				"traceparent": "00-" + testTraceID + "-" + testSpanID + "-00",
+				"b3":          testTraceID + "-" + testSpanID + "-1",
+			},
+			formats: []TraceHeaderFormat{TraceContextFormat, B3Format},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tc.header {
+				h.Set(k, v)
+			}
+			formats := tc.formats
+			if formats == nil {
+				formats = []TraceHeaderFormat{TraceContextFormat}
+			}
+			if got := exemplarFromTraceHeaders(h, formats); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMiddlewareAPI_WithExemplarFromTraceHeaders(t *testing.T) {
+	chain, reg := makeInstrumentedHandler(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}, WithExemplarFromTraceHeaders())
+
+	r, _ := http.NewRequest(http.MethodGet, "www.example.com", nil)
+	r.Header.Set("traceparent", "00-"+testTraceID+"-"+testSpanID+"-01")
+	chain.ServeHTTP(httptest.NewRecorder(), r)
+
+	// The order of exemplar labels is not deterministic, so they are
+	// compared as maps.
+	want := map[string]string{"trace_id": testTraceID, "span_id": testSpanID}
+	exemplars := gatherExemplars(t, reg)
+	if len(exemplars) == 0 {
+		t.Fatal("no exemplars found")
+	}
+	for _, got := range exemplars {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want exemplar %v, got %v", want, got)
+		}
+	}
+
+	// Unsampled traces do not result in exemplars.
+	chain, reg = makeInstrumentedHandler(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}, WithExemplarFromTraceHeaders())
+	r.Header.Set("traceparent", "00-"+testTraceID+"-"+testSpanID+"-00")
+	chain.ServeHTTP(httptest.NewRecorder(), r)
+	assetMetricAndExemplars(t, reg, 5, nil)
+
+	// An exemplar from the context takes precedence.
+	exemplar := prometheus.Labels{"traceID": "from context"}
+	chain, reg = makeInstrumentedHandler(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	},
+		WithExemplarFromContext(func(_ context.Context) prometheus.Labels { return exemplar }),
+		WithExemplarFromTraceHeaders(),
+	)
+	r.Header.Set("traceparent", "00-"+testTraceID+"-"+testSpanID+"-01")
+	chain.ServeHTTP(httptest.NewRecorder(), r)
+	assetMetricAndExemplars(t, reg, 5, labelsToLabelPair(exemplar))
+}
+
+func TestClientMiddlewareAPI_WithExemplarFromTraceHeaders(t *testing.T) {
+	client, reg := makeInstrumentedClient(WithExemplarFromTraceHeaders(B3Format))
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	req, err := http.NewRequest(http.MethodGet, backend.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("b3", testTraceID+"-"+testSpanID+"-1")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	want := map[string]string{"trace_id": testTraceID, "span_id": testSpanID}
+	exemplars := gatherExemplars(t, reg)
+	if len(exemplars) == 0 {
+		t.Fatal("no exemplars found")
+	}
+	for _, got := range exemplars {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want exemplar %v, got %v", want, got)
+		}
+	}
+}
+
+// gatherExemplars returns the labels of all exemplars of counters and
+// histogram buckets gathered from g.
+func gatherExemplars(t *testing.T, g prometheus.Gatherer) []map[string]string {
+	t.Helper()
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exemplars []map[string]string
+	add := func(e *dto.Exemplar) {
+		if e == nil {
+			return
+		}
+		labels := map[string]string{}
+		for _, lp := range e.GetLabel() {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		exemplars = append(exemplars, labels)
+	}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			add(m.GetCounter().GetExemplar())
+			for _, b := range m.GetHistogram().GetBucket() {
+				add(b.GetExemplar())
+			}
+		}
+	}
+	return exemplars
+}
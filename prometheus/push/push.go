@@ -40,10 +40,13 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/model"
@@ -82,6 +85,30 @@ type Pusher struct {
 	username, password string
 
 	expfmt expfmt.Format
+
+	// Change tracking, see SkipUnchanged.
+	skipUnchanged bool
+	maxInterval   time.Duration
+	now           func() time.Time // Only for testing.
+	mtx           sync.Mutex       // Protects the fields below.
+	lastHash      uint64
+	lastMethod    string
+	lastSent      time.Time
+	stats         Stats
+}
+
+// Stats counts the pushes of a Pusher with change tracking enabled, see
+// SkipUnchanged.
+type Stats struct {
+	// Sent is the number of pushes actually sent to the Pushgateway,
+	// including failed ones.
+	Sent uint64
+	// Skipped is the number of pushes skipped because the payload was
+	// unchanged since the last successful push.
+	Skipped uint64
+	// LastSent is the time of the last successful push, or the zero time if
+	// there was none yet.
+	LastSent time.Time
 }
 
 // New creates a new Pusher to push to the provided URL with the provided job
@@ -110,6 +137,7 @@ func New(url, job string) *Pusher {
 		registerer: reg,
 		client:     &http.Client{},
 		expfmt:     expfmt.NewFormat(expfmt.TypeProtoDelim),
+		now:        time.Now,
 	}
 }
 
@@ -229,6 +257,36 @@ func (p *Pusher) Format(format expfmt.Format) *Pusher {
 	return p
 }
 
+// SkipUnchanged enables change tracking: Push and Add (and their Context
+// variants) hash the encoded payload and skip the request, returning nil, if
+// the payload and the HTTP method are the same as for the last successful push.
+// This avoids needless requests from long-running processes that push
+// periodically, which would also update the push_time_seconds metric of the
+// Pushgateway. Note that the payload includes all gathered metrics, so any
+// changing metric (e.g. a timestamp or a counter) defeats the mechanism.
+//
+// If maxInterval is positive, a push is sent anyway once maxInterval has passed
+// since the last successful push, as a heartbeat that keeps push_time_seconds
+// reasonably current. Stats reports the number of skipped and sent pushes.
+//
+// A successful Delete resets the change tracking so that the next push is
+// always sent. For convenience, this method returns a pointer to the Pusher
+// itself.
+func (p *Pusher) SkipUnchanged(maxInterval time.Duration) *Pusher {
+	p.skipUnchanged = true
+	p.maxInterval = maxInterval
+	return p
+}
+
+// Stats returns the number of sent and skipped pushes. It may be called
+// concurrently with pushes. Pushes are only counted if change tracking is
+// enabled with SkipUnchanged.
+func (p *Pusher) Stats() Stats {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.stats
+}
+
 // Delete sends a “DELETE” request to the Pushgateway configured while creating
 // this Pusher, using the configured job name and any added grouping labels as
 // grouping key. Any added Gatherers and Collectors added to this Pusher are
@@ -259,6 +317,9 @@ func (p *Pusher) Delete() error {
 		body, _ := io.ReadAll(resp.Body) // Ignore any further error as this is for an error message only.
 		return fmt.Errorf("unexpected status code %d while deleting %s: %s", resp.StatusCode, p.fullURL(), body)
 	}
+	p.mtx.Lock()
+	p.lastMethod = ""
+	p.mtx.Unlock()
 	return nil
 }
 
@@ -293,6 +354,15 @@ func (p *Pusher) push(ctx context.Context, method string) error {
 				mf.GetName(), err)
 		}
 	}
+	var hash uint64
+	if p.skipUnchanged {
+		h := fnv.New64a()
+		h.Write(buf.Bytes())
+		hash = h.Sum64()
+		if p.unchanged(method, hash) {
+			return nil
+		}
+	}
 	req, err := http.NewRequestWithContext(ctx, method, p.fullURL(), buf)
 	if err != nil {
 		return err
@@ -314,9 +384,29 @@ func (p *Pusher) push(ctx context.Context, method string) error {
 		body, _ := io.ReadAll(resp.Body) // Ignore any further error as this is for an error message only.
 		return fmt.Errorf("unexpected status code %d while pushing to %s: %s", resp.StatusCode, p.fullURL(), body)
 	}
+	if p.skipUnchanged {
+		p.mtx.Lock()
+		p.lastHash, p.lastMethod, p.lastSent = hash, method, p.now()
+		p.stats.LastSent = p.lastSent
+		p.mtx.Unlock()
+	}
 	return nil
 }
 
+// unchanged returns whether a push with the provided method and payload hash
+// can be skipped, counting it as skipped or sent accordingly.
+func (p *Pusher) unchanged(method string, hash uint64) bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if method == p.lastMethod && hash == p.lastHash &&
+		(p.maxInterval <= 0 || p.now().Sub(p.lastSent) < p.maxInterval) {
+		p.stats.Skipped++
+		return true
+	}
+	p.stats.Sent++
+	return false
+}
+
 // fullURL assembles the URL used to push/delete metrics and returns it as a
 // string. The job name and any grouping label values containing a '/' will
 // trigger a base64 encoding of the affected component and proper suffixing of
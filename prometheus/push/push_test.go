@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/prometheus/common/expfmt"
 
@@ -307,3 +308,80 @@ func TestPush(t *testing.T) {
 		t.Error("empty Authorization header")
 	}
 }
+
+func TestPushSkipUnchanged(t *testing.T) {
+	var requests []string
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method)
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer pgw.Close()
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "testname",
+		Help: "testhelp",
+	})
+	now := time.Unix(1000, 0)
+	pusher := New(pgw.URL, "testjob").Collector(gauge).SkipUnchanged(time.Minute)
+	pusher.now = func() time.Time { return now }
+
+	steps := []struct {
+		name    string
+		do      func() error
+		advance time.Duration
+		wantReq string // Empty if the push is expected to be skipped.
+	}{
+		{name: "first push", do: pusher.Push, wantReq: http.MethodPut},
+		{name: "unchanged", do: pusher.Push, advance: 10 * time.Second},
+		{name: "unchanged again", do: pusher.Push, advance: 10 * time.Second},
+		{name: "other method", do: pusher.Add, wantReq: http.MethodPost},
+		{name: "changed", do: func() error { gauge.Set(42); return pusher.Add() }, wantReq: http.MethodPost},
+		{name: "unchanged after change", do: pusher.Add, advance: 59 * time.Second},
+		{name: "heartbeat", do: pusher.Add, advance: time.Second, wantReq: http.MethodPost},
+		{name: "unchanged after heartbeat", do: pusher.Add},
+		{name: "delete", do: pusher.Delete, wantReq: http.MethodDelete},
+		{name: "unchanged after delete", do: pusher.Add, wantReq: http.MethodPost},
+	}
+	for _, s := range steps {
+		requests = nil
+		now = now.Add(s.advance)
+		if err := s.do(); err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		var want []string
+		if s.wantReq != "" {
+			want = []string{s.wantReq}
+		}
+		if len(requests) != len(want) || (len(want) > 0 && requests[0] != want[0]) {
+			t.Errorf("%s: want requests %v, got %v", s.name, want, requests)
+		}
+	}
+
+	stats := pusher.Stats()
+	if stats.Sent != 5 || stats.Skipped != 4 {
+		t.Errorf("want 5 sent and 4 skipped pushes, got %d sent and %d skipped", stats.Sent, stats.Skipped)
+	}
+	if !stats.LastSent.Equal(now) {
+		t.Errorf("want last sent time %v, got %v", now, stats.LastSent)
+	}
+
+	// A failed push must not count as the last successful one.
+	failing := New("http://127.0.0.1:1", "testjob").Collector(prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "testname",
+		Help: "testhelp",
+	})).SkipUnchanged(0)
+	for i := 0; i < 2; i++ {
+		if err := failing.Push(); err == nil {
+			t.Fatal("expected error pushing to unreachable Pushgateway")
+		}
+	}
+	if stats := failing.Stats(); stats.Sent != 2 || stats.Skipped != 0 || !stats.LastSent.IsZero() {
+		t.Errorf("unexpected stats after failed pushes: %+v", stats)
+	}
+}
@@ -66,9 +66,11 @@ func init() {
 // pre-registered.
 func NewRegistry() *Registry {
 	return &Registry{
-		collectorsByID:  map[uint64]Collector{},
-		descIDs:         map[uint64]struct{}{},
-		dimHashesByName: map[string]uint64{},
+		collectorsByID:     map[uint64]Collector{},
+		descIDs:            map[uint64]struct{}{},
+		dimHashesByName:    map[string]uint64{},
+		collectorNames:     map[string]struct{}{},
+		namesByCollectorID: map[uint64]string{},
 	}
 }
 
@@ -264,10 +266,35 @@ type Registry struct {
 	dimHashesByName       map[string]uint64
 	uncheckedCollectors   []Collector
 	pedanticChecksEnabled bool
+
+	// collectorNames contains the names of all Collectors registered with
+	// RegisterNamed. namesByCollectorID and uncheckedNames map checked and
+	// unchecked Collectors, respectively, to their names. Unnamed
+	// Collectors have no entry in namesByCollectorID and an empty name in
+	// uncheckedNames.
+	collectorNames     map[string]struct{}
+	namesByCollectorID map[uint64]string
+	uncheckedNames     []string
 }
 
 // Register implements Registerer.
 func (r *Registry) Register(c Collector) error {
+	return r.register("", c)
+}
+
+// RegisterNamed works like Register but additionally associates the Collector
+// with the provided name, which must be unique within the Registry. Named
+// Collectors can be selected or excluded with GatherSelected, e.g. by
+// scrapers via the "collect[]" and "exclude[]" URL query parameters of an HTTP
+// handler created with promhttp.HandlerFor with EnableCollectorSelection set.
+func (r *Registry) RegisterNamed(name string, c Collector) error {
+	if name == "" {
+		return errors.New("collector name must not be empty")
+	}
+	return r.register(name, c)
+}
+
+func (r *Registry) register(collectorName string, c Collector) error {
 	var (
 		descChan           = make(chan *Desc, capDescChan)
 		newDescIDs         = map[uint64]struct{}{}
@@ -286,6 +313,9 @@ func (r *Registry) Register(c Collector) error {
 		}
 		r.mtx.Unlock()
 	}()
+	if _, exists := r.collectorNames[collectorName]; exists {
+		return fmt.Errorf("a collector named %q is already registered", collectorName)
+	}
 	// Conduct various tests...
 	for desc := range descChan {
 
@@ -329,6 +359,8 @@ func (r *Registry) Register(c Collector) error {
 	// A Collector yielding no Desc at all is considered unchecked.
 	if len(newDescIDs) == 0 {
 		r.uncheckedCollectors = append(r.uncheckedCollectors, c)
+		r.uncheckedNames = append(r.uncheckedNames, collectorName)
+		r.addCollectorName(collectorName)
 		return nil
 	}
 	if existing, exists := r.collectorsByID[collectorID]; exists {
@@ -359,9 +391,21 @@ func (r *Registry) Register(c Collector) error {
 	for name, dimHash := range newDimHashesByName {
 		r.dimHashesByName[name] = dimHash
 	}
+	if collectorName != "" {
+		r.namesByCollectorID[collectorID] = collectorName
+		r.addCollectorName(collectorName)
+	}
 	return nil
 }
 
+// addCollectorName records the provided Collector name (if not empty) as
+// used. The caller must hold the write lock.
+func (r *Registry) addCollectorName(name string) {
+	if name != "" {
+		r.collectorNames[name] = struct{}{}
+	}
+}
+
 // Unregister implements Registerer.
 func (r *Registry) Unregister(c Collector) bool {
 	var (
@@ -394,11 +438,276 @@ func (r *Registry) Unregister(c Collector) bool {
 	for id := range descIDs {
 		delete(r.descIDs, id)
 	}
+	r.removeCollectorName(collectorID)
 	// dimHashesByName is left untouched as those must be consistent
 	// throughout the lifetime of a program.
 	return true
 }
 
+// UnregisterByName unregisters all Collectors that yield a descriptor with the
+// provided fully-qualified metric name. It returns the number of unregistered
+// Collectors. See UnregisterMatching for details.
+func (r *Registry) UnregisterByName(fqName string) int {
+	return r.UnregisterMatching(func(name string) bool { return name == fqName })
+}
+
+// UnregisterMatching unregisters all Collectors that yield at least one
+// descriptor whose fully-qualified name is accepted by the provided match
+// function. Note that a Collector is unregistered as a whole, even if only some
+// of its descriptors match. UnregisterMatching returns the number of
+// unregistered Collectors. Unchecked Collectors are never unregistered.
+//
+// In contrast to Unregister, it is not necessary to have access to the
+// registered Collector (or an equal one). The price is that the Describe method
+// of all registered Collectors is called.
+//
+// The same restrictions as for Unregister apply, i.e. it is still not possible
+// to register a new Collector that is inconsistent with the unregistered
+// Collectors. Use Replace or ReplaceMatching with AllowIncompatible to change
+// the label names or help string of a metric.
+func (r *Registry) UnregisterMatching(match func(fqName string) bool) int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	ids := r.matchingCollectorIDs(match)
+	for _, id := range ids {
+		r.unregisterLocked(id)
+	}
+	return len(ids)
+}
+
+// ReplaceOpts configures how Replace and ReplaceMatching swap Collectors.
+type ReplaceOpts struct {
+	// AllowIncompatible allows the new Collector to yield descriptors with
+	// label names or a help string different from those previously
+	// registered with the same fully-qualified name. This is only
+	// permitted if no other remaining Collector yields descriptors with
+	// that name. Consumers of the exposed metrics must be prepared for the
+	// change, e.g. a change of histogram bucket boundaries or of the
+	// partitioning of a metric vector.
+	AllowIncompatible bool
+}
+
+// Replace unregisters the old Collector and registers the replacement in one
+// atomic step, i.e. a concurrent Gather either sees the old or the new
+// Collector, but never both or neither. The old Collector is identified in the
+// same way as by Unregister. An error is returned if the old Collector is not
+// registered. If the old Collector was registered with RegisterNamed, the
+// replacement is registered under the same name.
+//
+// The replacement is checked in the same way as by Register, with the
+// exception that it may use the descriptors yielded by the old Collector, and,
+// if AllowIncompatible is set in the provided ReplaceOpts, that it may change
+// the label names and help strings of the metrics it collects. If any check
+// fails, the old Collector stays registered.
+func (r *Registry) Replace(old, replacement Collector, opts ReplaceOpts) error {
+	var (
+		oldDescIDs  = map[uint64]struct{}{}
+		collectorID uint64
+	)
+	for _, desc := range describe(old) {
+		if _, exists := oldDescIDs[desc.id]; !exists {
+			collectorID ^= desc.id
+			oldDescIDs[desc.id] = struct{}{}
+		}
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, exists := r.collectorsByID[collectorID]; !exists || len(oldDescIDs) == 0 {
+		return errors.New("collector to be replaced is not registered")
+	}
+	return r.replaceLocked([]uint64{collectorID}, replacement, opts)
+}
+
+// ReplaceMatching works like Replace, but replaces all Collectors that would
+// be unregistered by UnregisterMatching with the same match function. It is
+// not an error if no Collector matches, in which case the replacement is
+// simply registered. ReplaceMatching returns the number of replaced
+// Collectors.
+func (r *Registry) ReplaceMatching(match func(fqName string) bool, replacement Collector, opts ReplaceOpts) (int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	ids := r.matchingCollectorIDs(match)
+	if err := r.replaceLocked(ids, replacement, opts); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// replaceLocked registers the replacement Collector in place of the registered
+// Collectors with the provided IDs. The caller must hold the write lock.
+func (r *Registry) replaceLocked(oldIDs []uint64, replacement Collector, opts ReplaceOpts) error {
+	var (
+		oldDescIDs         = map[uint64]struct{}{}
+		newDescIDs         = map[uint64]struct{}{}
+		newDimHashesByName = map[string]uint64{}
+		collectorID        uint64 // All desc IDs XOR'd together.
+		remainingNames     map[string]struct{}
+	)
+	for _, id := range oldIDs {
+		for _, desc := range describe(r.collectorsByID[id]) {
+			oldDescIDs[desc.id] = struct{}{}
+		}
+	}
+
+	for _, desc := range describe(replacement) {
+		if desc.err != nil {
+			return fmt.Errorf("descriptor %s is invalid: %w", desc, desc.err)
+		}
+		if _, exists := r.descIDs[desc.id]; exists {
+			if _, replaced := oldDescIDs[desc.id]; !replaced {
+				return fmt.Errorf("descriptor %s already exists with the same fully-qualified name and const label values", desc)
+			}
+		}
+		if _, exists := newDescIDs[desc.id]; !exists {
+			newDescIDs[desc.id] = struct{}{}
+			collectorID ^= desc.id
+		}
+
+		if dimHash, exists := newDimHashesByName[desc.fqName]; exists {
+			if dimHash != desc.dimHash {
+				return fmt.Errorf("descriptors reported by collector have inconsistent label names or help strings for the same fully-qualified name, offender is %s", desc)
+			}
+			continue
+		}
+		if dimHash, exists := r.dimHashesByName[desc.fqName]; exists && dimHash != desc.dimHash {
+			if !opts.AllowIncompatible {
+				return fmt.Errorf("a previously registered descriptor with the same fully-qualified name as %s has different label names or a different help string", desc)
+			}
+			if remainingNames == nil {
+				remainingNames = r.remainingNames(oldIDs)
+			}
+			if _, exists := remainingNames[desc.fqName]; exists {
+				return fmt.Errorf("a registered descriptor with the same fully-qualified name as %s has different label names or a different help string and is not replaced", desc)
+			}
+		}
+		newDimHashesByName[desc.fqName] = desc.dimHash
+	}
+
+	if len(newDescIDs) > 0 {
+		if existing, exists := r.collectorsByID[collectorID]; exists {
+			isOld := false
+			for _, id := range oldIDs {
+				isOld = isOld || id == collectorID
+			}
+			if !isOld {
+				if w, ok := existing.(*wrappingCollector); ok {
+					existing = w.unwrapRecursively()
+				}
+				return AlreadyRegisteredError{
+					ExistingCollector: existing,
+					NewCollector:      replacement,
+				}
+			}
+		}
+	}
+
+	// Only after all tests have passed, actually replace. A replacement
+	// of a single named Collector inherits its name.
+	var collectorName string
+	if len(oldIDs) == 1 {
+		collectorName = r.namesByCollectorID[oldIDs[0]]
+	}
+	for _, id := range oldIDs {
+		r.unregisterLocked(id)
+	}
+	r.addCollectorName(collectorName)
+	if len(newDescIDs) == 0 {
+		r.uncheckedCollectors = append(r.uncheckedCollectors, replacement)
+		r.uncheckedNames = append(r.uncheckedNames, collectorName)
+		return nil
+	}
+	r.collectorsByID[collectorID] = replacement
+	if collectorName != "" {
+		r.namesByCollectorID[collectorID] = collectorName
+	}
+	for id := range newDescIDs {
+		r.descIDs[id] = struct{}{}
+	}
+	for name, dimHash := range newDimHashesByName {
+		r.dimHashesByName[name] = dimHash
+	}
+	return nil
+}
+
+// matchingCollectorIDs returns the IDs of all registered Collectors yielding at
+// least one descriptor with a name accepted by match. The caller must hold the
+// lock.
+func (r *Registry) matchingCollectorIDs(match func(fqName string) bool) []uint64 {
+	var ids []uint64
+	for id, c := range r.collectorsByID {
+		for _, desc := range describe(c) {
+			if match(desc.fqName) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// remainingNames returns the fully-qualified names of the descriptors yielded
+// by all registered Collectors except those with the provided IDs. The caller
+// must hold the lock.
+func (r *Registry) remainingNames(excludedIDs []uint64) map[string]struct{} {
+	names := map[string]struct{}{}
+outer:
+	for id, c := range r.collectorsByID {
+		for _, excluded := range excludedIDs {
+			if id == excluded {
+				continue outer
+			}
+		}
+		for _, desc := range describe(c) {
+			names[desc.fqName] = struct{}{}
+		}
+	}
+	return names
+}
+
+// unregisterLocked removes the registered Collector with the provided ID. The
+// caller must hold the write lock.
+func (r *Registry) unregisterLocked(collectorID uint64) {
+	c, exists := r.collectorsByID[collectorID]
+	if !exists {
+		return
+	}
+	for _, desc := range describe(c) {
+		delete(r.descIDs, desc.id)
+	}
+	delete(r.collectorsByID, collectorID)
+	r.removeCollectorName(collectorID)
+}
+
+// removeCollectorName forgets the name of the checked Collector with the
+// provided ID (if any). The caller must hold the write lock.
+func (r *Registry) removeCollectorName(collectorID uint64) {
+	if name, ok := r.namesByCollectorID[collectorID]; ok {
+		delete(r.namesByCollectorID, collectorID)
+		delete(r.collectorNames, name)
+	}
+}
+
+// describe returns all descriptors yielded by the Describe method of the
+// provided Collector.
+func describe(c Collector) []*Desc {
+	var (
+		descChan = make(chan *Desc, capDescChan)
+		descs    []*Desc
+	)
+	go func() {
+		c.Describe(descChan)
+		close(descChan)
+	}()
+	for desc := range descChan {
+		descs = append(descs, desc)
+	}
+	return descs
+}
+
 // MustRegister implements Registerer.
 func (r *Registry) MustRegister(cs ...Collector) {
 	for _, c := range cs {
@@ -411,8 +720,58 @@ func (r *Registry) MustRegister(cs ...Collector) {
 // Gather implements Gatherer.
 func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
 	r.mtx.RLock()
+	return r.gatherRLocked(nil)
+}
+
+// GatherSelected implements SelectiveGatherer. Collectors registered with
+// Register (rather than RegisterNamed) are always collected.
+func (r *Registry) GatherSelected(include, exclude []string) ([]*dto.MetricFamily, error) {
+	r.mtx.RLock()
+	if len(include) == 0 && len(exclude) == 0 {
+		return r.gatherRLocked(nil)
+	}
+	for _, names := range [][]string{include, exclude} {
+		for _, name := range names {
+			if _, exists := r.collectorNames[name]; !exists {
+				r.mtx.RUnlock()
+				return nil, fmt.Errorf("%w: %q", ErrUnknownCollector, name)
+			}
+		}
+	}
+	return r.gatherRLocked(func(name string) bool {
+		if name == "" {
+			return true
+		}
+		if len(include) > 0 {
+			if _, ok := indexOf(name, include); !ok {
+				return false
+			}
+		}
+		_, excluded := indexOf(name, exclude)
+		return !excluded
+	})
+}
+
+// gatherRLocked gathers the metrics of all Collectors whose name is accepted by
+// the provided selected function, or of all Collectors if selected is nil. The
+// caller must hold the read lock, which is released by gatherRLocked.
+func (r *Registry) gatherRLocked(selected func(name string) bool) ([]*dto.MetricFamily, error) {
+	var (
+		checked   = make([]Collector, 0, len(r.collectorsByID))
+		unchecked = make([]Collector, 0, len(r.uncheckedCollectors))
+	)
+	for id, collector := range r.collectorsByID {
+		if selected == nil || selected(r.namesByCollectorID[id]) {
+			checked = append(checked, collector)
+		}
+	}
+	for i, collector := range r.uncheckedCollectors {
+		if selected == nil || selected(r.uncheckedNames[i]) {
+			unchecked = append(unchecked, collector)
+		}
+	}
 
-	if len(r.collectorsByID) == 0 && len(r.uncheckedCollectors) == 0 {
+	if len(checked) == 0 && len(unchecked) == 0 {
 		// Fast path.
 		r.mtx.RUnlock()
 		return nil, nil
@@ -427,14 +786,14 @@ func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
 		registeredDescIDs   map[uint64]struct{} // Only used for pedantic checks
 	)
 
-	goroutineBudget := len(r.collectorsByID) + len(r.uncheckedCollectors)
+	goroutineBudget := len(checked) + len(unchecked)
 	metricFamiliesByName := make(map[string]*dto.MetricFamily, len(r.dimHashesByName))
-	checkedCollectors := make(chan Collector, len(r.collectorsByID))
-	uncheckedCollectors := make(chan Collector, len(r.uncheckedCollectors))
-	for _, collector := range r.collectorsByID {
+	checkedCollectors := make(chan Collector, len(checked))
+	uncheckedCollectors := make(chan Collector, len(unchecked))
+	for _, collector := range checked {
 		checkedCollectors <- collector
 	}
-	for _, collector := range r.uncheckedCollectors {
+	for _, collector := range unchecked {
 		uncheckedCollectors <- collector
 	}
 	// In case pedantic checks are enabled, we have to copy the map before
@@ -724,6 +1083,25 @@ func processMetric(
 	return nil
 }
 
+// ErrUnknownCollector is returned (wrapped) by GatherSelected if a provided
+// Collector name is not registered.
+var ErrUnknownCollector = errors.New("unknown collector name")
+
+// SelectiveGatherer is a Gatherer that can restrict gathering to a subset of
+// its Collectors, selected by name. Collectors that are not selected are not
+// collected at all, which is different from filtering the gathered metric
+// families by name. This allows expensive Collectors to be collected less
+// frequently than cheap ones, e.g. by different scrape jobs.
+type SelectiveGatherer interface {
+	Gatherer
+	// GatherSelected works like Gather but only collects the named
+	// Collectors listed in include (or all named Collectors if include is
+	// empty) that are not listed in exclude. An error wrapping
+	// ErrUnknownCollector is returned if any of the provided names is
+	// unknown.
+	GatherSelected(include, exclude []string) ([]*dto.MetricFamily, error)
+}
+
 // Gatherers is a slice of Gatherer instances that implements the Gatherer
 // interface itself. Its Gather method calls Gather on all Gatherers in the
 // slice in order and returns the merged results. Errors returned from the
@@ -1060,8 +1438,22 @@ type TransactionalGatherer interface {
 	Gather() (_ []*dto.MetricFamily, done func(), err error)
 }
 
+// TransactionalSelectiveGatherer is the transactional variant of
+// SelectiveGatherer. See there and TransactionalGatherer for details.
+type TransactionalSelectiveGatherer interface {
+	TransactionalGatherer
+	// GatherSelected works like SelectiveGatherer.GatherSelected, with
+	// the same contract for done as TransactionalGatherer.Gather.
+	GatherSelected(include, exclude []string) (_ []*dto.MetricFamily, done func(), err error)
+}
+
 // ToTransactionalGatherer transforms Gatherer to transactional one with noop as done function.
+// If the provided Gatherer is a SelectiveGatherer, the returned
+// TransactionalGatherer is a TransactionalSelectiveGatherer.
 func ToTransactionalGatherer(g Gatherer) TransactionalGatherer {
+	if sg, ok := g.(SelectiveGatherer); ok {
+		return &noTransactionSelectiveGatherer{noTransactionGatherer: noTransactionGatherer{g: g}, sg: sg}
+	}
 	return &noTransactionGatherer{g: g}
 }
 
@@ -1074,3 +1466,14 @@ func (g *noTransactionGatherer) Gather() (_ []*dto.MetricFamily, done func(), er
 	mfs, err := g.g.Gather()
 	return mfs, func() {}, err
 }
+
+type noTransactionSelectiveGatherer struct {
+	noTransactionGatherer
+	sg SelectiveGatherer
+}
+
+// GatherSelected implements TransactionalSelectiveGatherer interface.
+func (g *noTransactionSelectiveGatherer) GatherSelected(include, exclude []string) (_ []*dto.MetricFamily, done func(), err error) {
+	mfs, err := g.sg.GatherSelected(include, exclude)
+	return mfs, func() {}, err
+}
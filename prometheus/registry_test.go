@@ -888,6 +888,208 @@ func TestRegisterUnregisterCollector(t *testing.T) {
 	}
 }
 
+func TestUnregisterByName(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "a_total", Help: "a", ConstLabels: prometheus.Labels{"x": "1"}}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "a_total", Help: "a", ConstLabels: prometheus.Labels{"x": "2"}}),
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "b", Help: "b"}),
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "c", Help: "c"}),
+	)
+
+	if got, want := reg.UnregisterByName("a_total"), 2; got != want {
+		t.Errorf("got %d unregistered collectors, want %d", got, want)
+	}
+	if got, want := reg.UnregisterByName("a_total"), 0; got != want {
+		t.Errorf("got %d unregistered collectors, want %d", got, want)
+	}
+	if got, want := reg.UnregisterMatching(func(name string) bool { return name == "b" || name == "d" }), 1; got != want {
+		t.Errorf("got %d unregistered collectors, want %d", got, want)
+	}
+	assertFamilyNames(t, reg, "c")
+
+	// The descriptors are free to be used again.
+	reg.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "a_total", Help: "a", ConstLabels: prometheus.Labels{"x": "1"}}))
+	// But they must still be consistent with previous registrations.
+	if err := reg.Register(prometheus.NewGauge(prometheus.GaugeOpts{Name: "b", Help: "different help"})); err == nil {
+		t.Error("expected error when registering an inconsistent descriptor")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	oldHist := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "h", Help: "h", Buckets: []float64{1, 2}})
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "g", Help: "g"})
+	reg.MustRegister(oldHist, other)
+
+	// A compatible replacement, e.g. with different buckets.
+	newHist := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "h", Help: "h", Buckets: []float64{1, 2, 5}})
+	if err := reg.Replace(oldHist, newHist, prometheus.ReplaceOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register(oldHist); err == nil {
+		t.Error("expected replacement with equal descriptors to be registered")
+	}
+
+	// Replacing a collector that is not registered fails.
+	if err := reg.Replace(prometheus.NewGauge(prometheus.GaugeOpts{Name: "x", Help: "x"}), newHist, prometheus.ReplaceOpts{}); err == nil {
+		t.Error("expected error when replacing an unregistered collector")
+	}
+
+	// Colliding with a collector that is not replaced fails.
+	if err := reg.Replace(newHist, prometheus.NewGauge(prometheus.GaugeOpts{Name: "g", Help: "g"}), prometheus.ReplaceOpts{}); err == nil {
+		t.Error("expected error when colliding with a remaining collector")
+	}
+
+	// Changing the label names requires AllowIncompatible.
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "h", Help: "h"}, []string{"code"})
+	if err := reg.Replace(newHist, vec, prometheus.ReplaceOpts{}); err == nil {
+		t.Error("expected error for incompatible replacement")
+	}
+	if err := reg.Replace(newHist, vec, prometheus.ReplaceOpts{AllowIncompatible: true}); err != nil {
+		t.Fatal(err)
+	}
+	vec.WithLabelValues("200").Observe(1)
+	assertFamilyNames(t, reg, "g", "h")
+
+	// AllowIncompatible does not apply to names used by remaining collectors.
+	if err := reg.Replace(vec, prometheus.NewGauge(prometheus.GaugeOpts{Name: "g", Help: "other help"}), prometheus.ReplaceOpts{AllowIncompatible: true}); err == nil {
+		t.Error("expected error for incompatible replacement of a remaining collector")
+	}
+
+	n, err := reg.ReplaceMatching(func(name string) bool { return name == "g" }, prometheus.NewGauge(prometheus.GaugeOpts{Name: "g2", Help: "g2"}), prometheus.ReplaceOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("got %d replaced collectors, want 1", n)
+	}
+	assertFamilyNames(t, reg, "g2", "h")
+}
+
+func TestReplaceConcurrentGather(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	newCounter := func() prometheus.Counter {
+		c := prometheus.NewCounter(prometheus.CounterOpts{Name: "c_total", Help: "c"})
+		c.Inc()
+		return c
+	}
+	current := newCounter()
+	reg.MustRegister(current)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			mfs, err := reg.Gather()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(mfs) != 1 || len(mfs[0].GetMetric()) != 1 {
+				t.Errorf("unexpected gather result during replacement: %v", mfs)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		next := newCounter()
+		if err := reg.Replace(current, next, prometheus.ReplaceOpts{}); err != nil {
+			t.Fatal(err)
+		}
+		current = next
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestGatherSelected(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	newGauge := func(name string) prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: name})
+	}
+
+	if err := reg.RegisterNamed("", newGauge("a")); err == nil {
+		t.Error("expected error registering with empty name")
+	}
+	reg.MustRegister(newGauge("unnamed"))
+	if err := reg.RegisterNamed("a", newGauge("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterNamed("b", newGauge("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterNamed("a", newGauge("c")); err == nil {
+		t.Error("expected error registering duplicate collector name")
+	}
+
+	for _, tc := range []struct {
+		include, exclude []string
+		want             []string
+	}{
+		{want: []string{"a", "b", "unnamed"}},
+		{include: []string{"a"}, want: []string{"a", "unnamed"}},
+		{exclude: []string{"a"}, want: []string{"b", "unnamed"}},
+		{include: []string{"a", "b"}, exclude: []string{"b"}, want: []string{"a", "unnamed"}},
+	} {
+		mfs, err := reg.GatherSelected(tc.include, tc.exclude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, mf := range mfs {
+			got = append(got, mf.GetName())
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("include %v, exclude %v: got metric families %v, want %v", tc.include, tc.exclude, got, tc.want)
+		}
+	}
+
+	if _, err := reg.GatherSelected([]string{"unknown"}, nil); !errors.Is(err, prometheus.ErrUnknownCollector) {
+		t.Errorf("got error %v, want %v", err, prometheus.ErrUnknownCollector)
+	}
+
+	// The name is carried over to the replacement of a named collector and
+	// released on unregistration.
+	b2 := newGauge("b2")
+	if _, err := reg.ReplaceMatching(func(n string) bool { return n == "b" }, b2, prometheus.ReplaceOpts{AllowIncompatible: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reg.GatherSelected([]string{"b"}, nil); err != nil {
+		t.Error(err)
+	}
+	reg.Unregister(b2)
+	if _, err := reg.GatherSelected([]string{"b"}, nil); !errors.Is(err, prometheus.ErrUnknownCollector) {
+		t.Errorf("got error %v, want %v", err, prometheus.ErrUnknownCollector)
+	}
+	if err := reg.RegisterNamed("b", newGauge("b")); err != nil {
+		t.Error(err)
+	}
+}
+
+func assertFamilyNames(t *testing.T, g prometheus.Gatherer, names ...string) {
+	t.Helper()
+
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, mf := range mfs {
+		got = append(got, mf.GetName())
+	}
+	if fmt.Sprint(got) != fmt.Sprint(names) {
+		t.Errorf("got metric families %v, want %v", got, names)
+	}
+}
+
 // TestHistogramVecRegisterGatherConcurrency is an end-to-end test that
 // concurrently calls Observe on random elements of a HistogramVec while the
 // same HistogramVec is registered concurrently and the Gather method of the
@@ -118,6 +118,11 @@ type SummaryOpts struct {
 	// https://prometheus.io/docs/instrumenting/writing_exporters/#target-labels-not-static-scraped-labels
 	ConstLabels Labels
 
+	// ConstLabelSet is an alternative to ConstLabels for callers holding the
+	// constant labels as LabelSet. Both are merged, with the value in
+	// ConstLabelSet taking precedence if a label name occurs in both.
+	ConstLabelSet LabelSet
+
 	// Objectives defines the quantile rank estimates with their respective
 	// absolute error. If Objectives[q] = e, then the value reported for q
 	// will be the φ-quantile value for some φ between q-e and q+e.  The
@@ -185,7 +190,7 @@ func NewSummary(opts SummaryOpts) Summary {
 			BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
 			opts.Help,
 			nil,
-			opts.ConstLabels,
+			opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 		),
 		opts,
 	)
@@ -577,7 +582,7 @@ func (v2) NewSummaryVec(opts SummaryVecOpts) *SummaryVec {
 		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
 		opts.Help,
 		opts.VariableLabels,
-		opts.ConstLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 	)
 	return &SummaryVec{
 		MetricVec: NewMetricVec(desc, func(lvs ...string) Metric {
@@ -663,6 +668,30 @@ func (v *SummaryVec) With(labels Labels) Observer {
 	return s
 }
 
+// GetMetricWithLabelSet works like GetMetricWith, but with the labels provided
+// as LabelSet, which avoids allocations on hot paths.
+func (v *SummaryVec) GetMetricWithLabelSet(ls LabelSet) (Observer, error) {
+	metric, err := v.MetricVec.GetMetricWithLabelSet(ls)
+	if metric != nil {
+		return metric.(Observer), err
+	}
+	return nil, err
+}
+
+// WithLabelSet works as GetMetricWithLabelSet, but panics where
+// GetMetricWithLabelSet would have returned an error. A LabelSet can be created
+// once and reused, like
+//
+//	notFound := prometheus.NewLabelSet("code", "404", "method", "GET")
+//	myVec.WithLabelSet(notFound).Observe(42.21)
+func (v *SummaryVec) WithLabelSet(ls LabelSet) Observer {
+	s, err := v.GetMetricWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
 // CurryWith returns a vector curried with the provided labels, i.e. the
 // returned vector has those labels pre-set for all labeled operations performed
 // on it. The cardinality of the curried vector is reduced accordingly. The
@@ -694,6 +723,26 @@ func (v *SummaryVec) MustCurryWith(labels Labels) ObserverVec {
 	return vec
 }
 
+// CurryWithLabelSet works like CurryWith, but with the labels provided as
+// LabelSet.
+func (v *SummaryVec) CurryWithLabelSet(ls LabelSet) (ObserverVec, error) {
+	vec, err := v.MetricVec.CurryWithLabelSet(ls)
+	if vec != nil {
+		return &SummaryVec{vec}, err
+	}
+	return nil, err
+}
+
+// MustCurryWithLabelSet works as CurryWithLabelSet but panics where
+// CurryWithLabelSet would have returned an error.
+func (v *SummaryVec) MustCurryWithLabelSet(ls LabelSet) ObserverVec {
+	vec, err := v.CurryWithLabelSet(ls)
+	if err != nil {
+		panic(err)
+	}
+	return vec
+}
+
 type constSummary struct {
 	desc       *Desc
 	count      uint64
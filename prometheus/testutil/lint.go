@@ -44,3 +44,14 @@ func GatherAndLint(g prometheus.Gatherer, metricNames ...string) ([]promlint.Pro
 	}
 	return promlint.NewWithMetricFamilies(got).Lint()
 }
+
+// GatherAndLintRules gathers all metrics from the provided Gatherer and checks
+// the provided alerting and recording rules against them with the rule linter
+// in the promlint package.
+func GatherAndLintRules(g prometheus.Gatherer, rules []promlint.Rule) ([]promlint.RuleProblem, error) {
+	got, err := g.Gather()
+	if err != nil {
+		return nil, fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	return promlint.NewRuleLinterWithMetricFamilies(got, rules).Lint()
+}
@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
 )
 
 func TestCollectAndLintGood(t *testing.T) {
@@ -69,3 +70,22 @@ func TestCollectAndLintBad(t *testing.T) {
 		t.Error("Not enough lint problems found.")
 	}
 }
+
+func TestGatherAndLintRules(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "queue_length",
+		Help: "Current queue length.",
+	}))
+
+	problems, err := GatherAndLintRules(reg, []promlint.Rule{
+		{Alert: "QueueFull", Expr: "queue_length > 100"},
+		{Alert: "QueueGrowing", Expr: "rate(queue_length[5m]) > 0"},
+	})
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if len(problems) != 1 || problems[0].Rule.Alert != "QueueGrowing" {
+		t.Error("Unexpected lint problems:", problems)
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promlint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This file contains a deliberately minimal PromQL scanner. It does not
+// validate expressions, it only extracts the vector selectors and the function
+// calls they are nested in, which is all the rule linter needs. Pulling in the
+// full PromQL parser would add the Prometheus server as a dependency.
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+}
+
+// tokenize splits a PromQL expression into tokens. String tokens are
+// unquoted.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(expr) && expr[i] != '\n' {
+				i++
+			}
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(expr) && (isIdentStart(expr[j]) || isDigit(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, expr[i:j]})
+			i = j
+		case isDigit(c) || (c == '.' && i+1 < len(expr) && isDigit(expr[i+1])):
+			// Numbers, including hex, exponents, and durations like 1h30m.
+			j := i + 1
+			for j < len(expr) {
+				d := expr[j]
+				if isDigit(d) || isIdentStart(d) || d == '.' {
+					j++
+					continue
+				}
+				if (d == '+' || d == '-') && (expr[j-1] == 'e' || expr[j-1] == 'E') && !strings.HasPrefix(expr[i:], "0x") {
+					j++
+					continue
+				}
+				break
+			}
+			tokens = append(tokens, token{tokNumber, expr[i:j]})
+			i = j
+		case c == '"' || c == '\'' || c == '`':
+			j := i + 1
+			for j < len(expr) && expr[j] != c {
+				if expr[j] == '\\' && c != '`' {
+					j++
+				}
+				j++
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("unterminated string starting at position %d", i)
+			}
+			s := expr[i : j+1]
+			if c == '\'' {
+				// strconv.Unquote only supports single-quoted runes.
+				s = `"` + strings.ReplaceAll(s[1:len(s)-1], `"`, `\"`) + `"`
+			}
+			unquoted, err := strconv.Unquote(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", i, err)
+			}
+			tokens = append(tokens, token{tokString, unquoted})
+			i = j + 1
+		default:
+			if i+1 < len(expr) {
+				switch expr[i : i+2] {
+				case "=~", "!~", "!=", "==", ">=", "<=":
+					tokens = append(tokens, token{tokPunct, expr[i : i+2]})
+					i += 2
+					continue
+				}
+			}
+			tokens = append(tokens, token{tokPunct, expr[i : i+1]})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// keywords are identifiers that are never vector selectors.
+var keywords = map[string]struct{}{
+	"and": {}, "or": {}, "unless": {}, "atan2": {},
+	"by": {}, "without": {}, "on": {}, "ignoring": {},
+	"group_left": {}, "group_right": {}, "bool": {}, "offset": {},
+	"inf": {}, "nan": {},
+}
+
+// labelListKeywords are keywords that may be followed by a parenthesized list
+// of label names.
+var labelListKeywords = map[string]struct{}{
+	"by": {}, "without": {}, "on": {}, "ignoring": {}, "group_left": {}, "group_right": {},
+}
+
+// labelMatcher is a single label matcher of a vector selector.
+type labelMatcher struct {
+	name, op, value string
+}
+
+// selector is a vector selector found in a PromQL expression.
+type selector struct {
+	metric   string
+	matchers []labelMatcher
+	// function is the name of the function or aggregation the selector is
+	// directly passed to, if any.
+	function string
+	// quantileArg is true if the selector is part of the second argument
+	// of a histogram_quantile call, possibly nested in further calls.
+	quantileArg bool
+}
+
+// callFrame is an open parenthesis while scanning an expression.
+type callFrame struct {
+	function string
+	arg      int
+}
+
+// extractSelectors returns all vector selectors of the provided PromQL
+// expression.
+func extractSelectors(expr string) ([]selector, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		selectors []selector
+		stack     []callFrame
+		// pending is an aggregation whose grouping clause precedes the
+		// parenthesized argument, as in "sum by (job) (x)".
+		pending string
+	)
+	peek := func(i int) token {
+		if i < len(tokens) {
+			return tokens[i]
+		}
+		return token{}
+	}
+	isPunct := func(t token, v string) bool { return t.kind == tokPunct && t.val == v }
+	// skipTo returns the index of the token closing the bracket opened at
+	// tokens[i].
+	skipTo := func(i int, closing string) int {
+		for i < len(tokens) && !isPunct(tokens[i], closing) {
+			i++
+		}
+		return i
+	}
+	newSelector := func() selector {
+		s := selector{}
+		if len(stack) > 0 {
+			s.function = stack[len(stack)-1].function
+		}
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j].function == "histogram_quantile" {
+				s.quantileArg = stack[j].arg >= 1
+				break
+			}
+		}
+		return s
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.kind == tokIdent:
+			lower := strings.ToLower(t.val)
+			if _, ok := labelListKeywords[lower]; ok {
+				if isPunct(peek(i+1), "(") {
+					i = skipTo(i+1, ")")
+				}
+				continue
+			}
+			if _, ok := keywords[lower]; ok {
+				continue
+			}
+			if isPunct(peek(i+1), "(") {
+				stack = append(stack, callFrame{function: t.val})
+				i++
+				continue
+			}
+			if next := peek(i + 1); next.kind == tokIdent {
+				if n := strings.ToLower(next.val); n == "by" || n == "without" {
+					pending = t.val
+					continue
+				}
+			}
+			s := newSelector()
+			s.metric = t.val
+			if isPunct(peek(i+1), "{") {
+				end := skipTo(i+1, "}")
+				s.matchers, s.metric = parseMatchers(tokens[i+2:end], s.metric)
+				i = end
+			}
+			selectors = append(selectors, s)
+		case isPunct(t, "{"):
+			end := skipTo(i, "}")
+			s := newSelector()
+			s.matchers, s.metric = parseMatchers(tokens[i+1:end], "")
+			selectors = append(selectors, s)
+			i = end
+		case isPunct(t, "["):
+			// Range or subquery duration.
+			i = skipTo(i, "]")
+		case isPunct(t, "@"):
+			// The @ modifier takes a number or start()/end().
+			if next := peek(i + 1); next.kind == tokIdent && isPunct(peek(i+2), "(") {
+				i = skipTo(i+2, ")")
+			}
+		case isPunct(t, "("):
+			stack = append(stack, callFrame{function: pending})
+			pending = ""
+		case isPunct(t, ")"):
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case isPunct(t, ","):
+			if len(stack) > 0 {
+				stack[len(stack)-1].arg++
+			}
+		}
+	}
+	return selectors, nil
+}
+
+// parseMatchers parses the tokens between the braces of a vector selector. It
+// returns the label matchers and the metric name, which is either the provided
+// one, a quoted name without operator, or the value of an equality matcher on
+// __name__.
+func parseMatchers(tokens []token, metric string) ([]labelMatcher, string) {
+	var matchers []labelMatcher
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind != tokIdent && t.kind != tokString {
+			continue
+		}
+		op := tokens[min(i+1, len(tokens)-1)]
+		if i+2 >= len(tokens) || op.kind != tokPunct || tokens[i+2].kind != tokString {
+			if t.kind == tokString && metric == "" {
+				metric = t.val
+			}
+			continue
+		}
+		switch op.val {
+		case "=", "!=", "=~", "!~":
+		default:
+			continue
+		}
+		if t.val == "__name__" {
+			if op.val == "=" && metric == "" {
+				metric = tokens[i+2].val
+			}
+		} else {
+			matchers = append(matchers, labelMatcher{name: t.val, op: op.val, value: tokens[i+2].val})
+		}
+		i += 2
+	}
+	return matchers, metric
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promlint
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// A Rule is an alerting or recording rule whose expression is checked by a
+// RuleLinter. This package does not read rule files, so that it does not
+// depend on a YAML library. Decode rule files with the YAML library of your
+// choice (or with the rulefmt package of Prometheus) and convert each rule of
+// each group into a Rule.
+type Rule struct {
+	// File and Group identify where the rule has been defined. They are
+	// only used for reporting.
+	File  string
+	Group string
+	// Alert is the name of an alerting rule, Record the name of the series
+	// a recording rule records. Exactly one of them is set.
+	Alert  string
+	Record string
+	// Expr is the PromQL expression of the rule.
+	Expr string
+}
+
+// Name returns the alert name or the recorded series name of the rule.
+func (r Rule) Name() string {
+	if r.Record != "" {
+		return r.Record
+	}
+	return r.Alert
+}
+
+// A RuleProblem is an issue with a rule expression detected by a RuleLinter.
+// The embedded Problem's Metric is the name of the metric referenced by the
+// expression.
+type RuleProblem struct {
+	Problem
+	Rule Rule
+}
+
+// DefaultTargetLabels are the labels that Prometheus attaches to all scraped
+// series and that are therefore not exposed by the service itself.
+var DefaultTargetLabels = []string{"job", "instance"}
+
+// builtinMetrics are series that are generated by Prometheus itself rather than
+// exposed by a service.
+var builtinMetrics = []string{
+	"up",
+	"scrape_duration_seconds",
+	"scrape_samples_scraped",
+	"scrape_samples_post_metric_relabeling",
+	"scrape_series_added",
+	"ALERTS",
+	"ALERTS_FOR_STATE",
+}
+
+// A RuleLinter checks the expressions of alerting and recording rules against
+// the metrics exposed by a service. It reports references to metric names and
+// label names that are not exposed, rate-like functions applied to gauges, and
+// histogram_quantile applied to series that are neither classic histogram
+// buckets nor native histograms.
+//
+// Metrics recorded by one of the linted recording rules and series generated by
+// Prometheus itself, like "up", are considered known. Labels of recorded
+// metrics are not checked.
+type RuleLinter struct {
+	// See Linter for the semantics of r and mfs.
+	r     io.Reader
+	mfs   []*dto.MetricFamily
+	rules []Rule
+
+	targetLabels []string
+}
+
+// NewRuleLinter creates a new RuleLinter that checks the provided rules against
+// an input stream of Prometheus metrics in the Prometheus text exposition
+// format, e.g. the body of a scrape.
+func NewRuleLinter(r io.Reader, rules []Rule) *RuleLinter {
+	return &RuleLinter{
+		r:            r,
+		rules:        rules,
+		targetLabels: DefaultTargetLabels,
+	}
+}
+
+// NewRuleLinterWithMetricFamilies creates a new RuleLinter that checks the
+// provided rules against a slice of MetricFamily protobuf messages, e.g. as
+// returned by a Gatherer.
+func NewRuleLinterWithMetricFamilies(mfs []*dto.MetricFamily, rules []Rule) *RuleLinter {
+	return &RuleLinter{
+		mfs:          mfs,
+		rules:        rules,
+		targetLabels: DefaultTargetLabels,
+	}
+}
+
+// SetTargetLabels replaces DefaultTargetLabels with the provided labels as the
+// labels that are accepted in selectors although they are not exposed by the
+// service, e.g. because they are added by relabeling.
+func (l *RuleLinter) SetTargetLabels(labels ...string) {
+	l.targetLabels = labels
+}
+
+// exposedSeries is a series name exposed by a metric family.
+type exposedSeries struct {
+	family *dto.MetricFamily
+	labels map[string]struct{}
+}
+
+// Lint performs a linting pass, returning a slice of RuleProblems indicating any
+// issues found in the rule expressions. The slice is sorted by file, group,
+// rule name, metric name and issue description.
+func (l *RuleLinter) Lint() ([]RuleProblem, error) {
+	mfs := l.mfs
+	if l.r != nil {
+		d := expfmt.NewDecoder(l.r, expfmt.NewFormat(expfmt.TypeTextPlain))
+		for {
+			mf := &dto.MetricFamily{}
+			if err := d.Decode(mf); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			mfs = append(mfs, mf)
+		}
+	}
+
+	exposed := map[string]*exposedSeries{}
+	for _, mf := range mfs {
+		addExposedSeries(exposed, mf)
+	}
+	known := map[string]struct{}{}
+	for _, name := range builtinMetrics {
+		known[name] = struct{}{}
+	}
+	for _, r := range l.rules {
+		if r.Record != "" {
+			known[r.Record] = struct{}{}
+		}
+	}
+	targetLabels := map[string]struct{}{}
+	for _, ln := range l.targetLabels {
+		targetLabels[ln] = struct{}{}
+	}
+
+	var problems []RuleProblem
+	for _, r := range l.rules {
+		selectors, err := extractSelectors(r.Expr)
+		if err != nil {
+			problems = append(problems, RuleProblem{
+				Problem: Problem{Text: fmt.Sprintf("cannot parse expression: %v", err)},
+				Rule:    r,
+			})
+			continue
+		}
+		for _, s := range selectors {
+			for _, text := range lintSelector(s, exposed, known, targetLabels) {
+				problems = append(problems, RuleProblem{
+					Problem: Problem{Metric: s.metric, Text: text},
+					Rule:    r,
+				})
+			}
+		}
+	}
+
+	// Ensure deterministic output.
+	sort.SliceStable(problems, func(i, j int) bool {
+		pi, pj := problems[i], problems[j]
+		switch {
+		case pi.Rule.File != pj.Rule.File:
+			return pi.Rule.File < pj.Rule.File
+		case pi.Rule.Group != pj.Rule.Group:
+			return pi.Rule.Group < pj.Rule.Group
+		case pi.Rule.Name() != pj.Rule.Name():
+			return pi.Rule.Name() < pj.Rule.Name()
+		case pi.Metric != pj.Metric:
+			return pi.Metric < pj.Metric
+		}
+		return pi.Text < pj.Text
+	})
+	return problems, nil
+}
+
+// addExposedSeries adds the series names exposed by the provided metric family,
+// e.g. the _bucket, _sum, and _count series of a classic histogram.
+func addExposedSeries(exposed map[string]*exposedSeries, mf *dto.MetricFamily) {
+	add := func(name string, extraLabels ...string) {
+		s, ok := exposed[name]
+		if !ok {
+			s = &exposedSeries{family: mf, labels: map[string]struct{}{}}
+			exposed[name] = s
+		}
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				s.labels[lp.GetName()] = struct{}{}
+			}
+		}
+		for _, ln := range extraLabels {
+			s.labels[ln] = struct{}{}
+		}
+	}
+
+	name := mf.GetName()
+	switch mf.GetType() {
+	case dto.MetricType_SUMMARY:
+		add(name, "quantile")
+		add(name + "_sum")
+		add(name + "_count")
+	case dto.MetricType_HISTOGRAM:
+		if isNativeHistogram(mf) {
+			add(name)
+		}
+		add(name+"_bucket", "le")
+		add(name + "_sum")
+		add(name + "_count")
+	case dto.MetricType_GAUGE_HISTOGRAM:
+		if isNativeHistogram(mf) {
+			add(name)
+		}
+		add(name+"_bucket", "le")
+		add(name + "_gsum")
+		add(name + "_gcount")
+	default:
+		add(name)
+	}
+}
+
+func isNativeHistogram(mf *dto.MetricFamily) bool {
+	for _, m := range mf.GetMetric() {
+		if m.GetHistogram().Schema != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// rateFunctions are the functions that should only be applied to counters.
+var rateFunctions = map[string]struct{}{
+	"rate":     {},
+	"irate":    {},
+	"increase": {},
+	"resets":   {},
+}
+
+// lintSelector returns the problems with a single selector of a rule
+// expression.
+func lintSelector(s selector, exposed map[string]*exposedSeries, known, targetLabels map[string]struct{}) []string {
+	if s.metric == "" {
+		// Selectors matching on labels only cannot be checked.
+		return nil
+	}
+	series, ok := exposed[s.metric]
+	if !ok {
+		if _, ok := known[s.metric]; ok {
+			return nil
+		}
+		return []string{"metric is not exposed"}
+	}
+
+	var problems []string
+	for _, m := range s.matchers {
+		if _, ok := series.labels[m.name]; ok {
+			continue
+		}
+		if _, ok := targetLabels[m.name]; ok {
+			continue
+		}
+		if m.op == "=" && m.value == "" {
+			// Matching on the absence of a label is fine.
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("label %q is not exposed", m.name))
+	}
+
+	typ := series.family.GetType()
+	if _, ok := rateFunctions[s.function]; ok && typ == dto.MetricType_GAUGE {
+		problems = append(problems, fmt.Sprintf("%s() should only be used with counters, but the metric is a gauge", s.function))
+	}
+	if s.quantileArg && !strings.HasSuffix(s.metric, "_bucket") {
+		isNative := (typ == dto.MetricType_HISTOGRAM || typ == dto.MetricType_GAUGE_HISTOGRAM) && s.metric == series.family.GetName()
+		if !isNative {
+			problems = append(problems, `histogram_quantile() should only be used with "_bucket" series or native histograms`)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promlint_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
+)
+
+const ruleLintMetrics = `
+# HELP http_requests_total Total requests.
+# TYPE http_requests_total counter
+http_requests_total{code="200",handler="/"} 10
+# HELP queue_length Current queue length.
+# TYPE queue_length gauge
+queue_length{queue="a"} 3
+# HELP request_duration_seconds Request duration.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 1
+request_duration_seconds_bucket{le="+Inf"} 2
+request_duration_seconds_sum 0.3
+request_duration_seconds_count 2
+`
+
+var ruleLintRules = []promlint.Rule{
+	{Group: "example", Record: "job:http_requests:rate5m", Expr: `sum by (job) (rate(http_requests_total{code=~"5..", instance!=""}[5m]))`},
+	{Group: "example", Alert: "HighErrorRate", Expr: `job:http_requests:rate5m > 0.1 and on(job) up == 1`},
+	{Group: "example", Alert: "RenamedMetric", Expr: `rate(http_requests_count[5m]) > 1`},
+	{Group: "example", Alert: "RenamedLabel", Expr: `http_requests_total{status="500", absent=""} > 0`},
+	{Group: "example", Alert: "GaugeRate", Expr: `rate(queue_length{queue="a"}[5m]) > 0`},
+	{Group: "example", Alert: "SlowRequests", Expr: `histogram_quantile(0.99, sum by (le) (rate(request_duration_seconds_bucket[5m]))) > 1`},
+	{Group: "example", Alert: "WrongQuantile", Expr: `histogram_quantile(0.99, rate(request_duration_seconds_sum[5m])) > 1`},
+	{Group: "example", Alert: "Unparsable", Expr: `up{job="unterminated}`},
+}
+
+func TestRuleLinter(t *testing.T) {
+	problems, err := promlint.NewRuleLinter(strings.NewReader(ruleLintMetrics), ruleLintRules).Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, fmt.Sprintf("%s/%s: %s: %s", p.Rule.Group, p.Rule.Name(), p.Metric, p.Text))
+	}
+	expected := []string{
+		`example/GaugeRate: queue_length: rate() should only be used with counters, but the metric is a gauge`,
+		`example/RenamedLabel: http_requests_total: label "status" is not exposed`,
+		`example/RenamedMetric: http_requests_count: metric is not exposed`,
+		`example/Unparsable: : cannot parse expression: unterminated string starting at position 7`,
+		`example/WrongQuantile: request_duration_seconds_sum: histogram_quantile() should only be used with "_bucket" series or native histograms`,
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got problems\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestRuleLinterTargetLabels(t *testing.T) {
+	rules := []promlint.Rule{{Alert: "Down", Expr: `queue_length{queue="a", cluster="eu"} == 0`}}
+
+	problems, err := promlint.NewRuleLinter(strings.NewReader(ruleLintMetrics), rules).Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 {
+		t.Fatalf("got problems %v, want exactly one", problems)
+	}
+
+	l := promlint.NewRuleLinter(strings.NewReader(ruleLintMetrics), rules)
+	l.SetTargetLabels("job", "instance", "cluster")
+	if problems, err = l.Lint(); err != nil || len(problems) != 0 {
+		t.Errorf("got problems %v (err: %v), want none", problems, err)
+	}
+}
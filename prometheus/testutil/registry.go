@@ -0,0 +1,174 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultRegistryEnv is set for the duration of a test that uses
+// UseDefaultRegistry. Setting an environment variable via testing.TB.Setenv is
+// the only way to make the testing package reject t.Parallel for a test.
+const defaultRegistryEnv = "PROMETHEUS_TESTUTIL_DEFAULT_REGISTRY"
+
+// LeakCheck determines which registrations are reported as leaked once a test
+// using UseDefaultRegistry or NewRegistry finishes.
+type LeakCheck int
+
+const (
+	// IgnoreLeaks disables leak detection.
+	IgnoreLeaks LeakCheck = iota
+	// ReportEscapedRegistrations reports Collectors that got registered with
+	// the previously installed DefaultRegisterer while the test registry was
+	// in place. This happens if the code under test has kept a reference to
+	// the original DefaultRegisterer, e.g. in a package-level variable, and
+	// therefore bypasses the test registry. It is only meaningful for
+	// UseDefaultRegistry and only works if the previous DefaultRegisterer
+	// is also a prometheus.Collector, as is the case for a
+	// prometheus.Registry.
+	ReportEscapedRegistrations
+	// ReportAllLeaks additionally reports Collectors that are still
+	// registered with the test registry when the test finishes. Use it to
+	// verify that the code under test unregisters everything it has
+	// registered, e.g. when a component is shut down.
+	ReportAllLeaks
+)
+
+// RegistryOpts bundles the options for UseDefaultRegistry and NewRegistry.
+type RegistryOpts struct {
+	// Pedantic makes the test registry a pedantic one, see
+	// prometheus.NewPedanticRegistry.
+	Pedantic bool
+	// LeakCheck selects which leaked registrations are reported as test
+	// errors once the test finishes. The default is IgnoreLeaks.
+	LeakCheck LeakCheck
+}
+
+// UseDefaultRegistry installs a freshly created prometheus.Registry as
+// prometheus.DefaultRegisterer and prometheus.DefaultGatherer for the duration
+// of the provided test. The previously installed values are restored once the
+// test and all its subtests have finished. The new Registry is returned for
+// inspection, e.g. with GatherAndCompare.
+//
+// This is useful to test code that registers metrics with the default
+// registry, e.g. via prometheus.MustRegister or promauto.NewCounter, which
+// would otherwise panic with an AlreadyRegisteredError on repeated setup.
+//
+// As the default registry is global state, tests using UseDefaultRegistry
+// must not run in parallel with other tests. UseDefaultRegistry enforces this
+// the same way testing.T.Setenv does: It panics if the test or one of its
+// ancestors has called t.Parallel, and a subsequent call of t.Parallel panics.
+// Parallel tests should use NewRegistry instead and inject the returned
+// Registry into the code under test explicitly, e.g. via promauto.With.
+func UseDefaultRegistry(t testing.TB, opts RegistryOpts) *prometheus.Registry {
+	t.Helper()
+
+	t.Setenv(defaultRegistryEnv, t.Name())
+
+	reg := newRegistry(opts)
+	prevRegisterer, prevGatherer := prometheus.DefaultRegisterer, prometheus.DefaultGatherer
+
+	var before map[string]struct{}
+	prevCollector, checkEscaped := prevRegisterer.(prometheus.Collector)
+	checkEscaped = checkEscaped && opts.LeakCheck >= ReportEscapedRegistrations
+	if checkEscaped {
+		before = descStrings(prevCollector)
+	}
+
+	prometheus.DefaultRegisterer, prometheus.DefaultGatherer = reg, reg
+	t.Cleanup(func() {
+		prometheus.DefaultRegisterer, prometheus.DefaultGatherer = prevRegisterer, prevGatherer
+
+		if checkEscaped {
+			var escaped []string
+			for d := range descStrings(prevCollector) {
+				if _, ok := before[d]; !ok {
+					escaped = append(escaped, d)
+				}
+			}
+			reportLeaks(t, "registered with the previous default registerer", escaped)
+		}
+		if opts.LeakCheck >= ReportAllLeaks {
+			reportLeaks(t, "still registered with the test registry", sortedKeys(descStrings(reg)))
+		}
+	})
+	return reg
+}
+
+// NewRegistry creates a new prometheus.Registry for use in the provided test,
+// which may run in parallel with other tests. Unlike UseDefaultRegistry, it
+// leaves prometheus.DefaultRegisterer and prometheus.DefaultGatherer alone, so
+// the returned Registry has to be injected into the code under test
+// explicitly. Only ReportAllLeaks has an effect as LeakCheck, in which case
+// Collectors still registered with the returned Registry are reported once
+// the test has finished.
+func NewRegistry(t testing.TB, opts RegistryOpts) *prometheus.Registry {
+	t.Helper()
+
+	reg := newRegistry(opts)
+	if opts.LeakCheck >= ReportAllLeaks {
+		t.Cleanup(func() {
+			reportLeaks(t, "still registered with the test registry", sortedKeys(descStrings(reg)))
+		})
+	}
+	return reg
+}
+
+func newRegistry(opts RegistryOpts) *prometheus.Registry {
+	if opts.Pedantic {
+		return prometheus.NewPedanticRegistry()
+	}
+	return prometheus.NewRegistry()
+}
+
+// descStrings returns the string representations of all descriptors of the
+// provided Collector.
+func descStrings(c prometheus.Collector) map[string]struct{} {
+	var (
+		descs = map[string]struct{}{}
+		ch    = make(chan *prometheus.Desc)
+		done  = make(chan struct{})
+	)
+	go func() {
+		for d := range ch {
+			descs[d.String()] = struct{}{}
+		}
+		close(done)
+	}()
+	c.Describe(ch)
+	close(ch)
+	<-done
+	return descs
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func reportLeaks(t testing.TB, what string, descs []string) {
+	if len(descs) == 0 {
+		return
+	}
+	sort.Strings(descs)
+	t.Errorf("%d leaked metric descriptor(s) %s:\n\t%s", len(descs), what, strings.Join(descs, "\n\t"))
+}
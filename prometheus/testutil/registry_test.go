@@ -0,0 +1,117 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// errorRecorder is a testing.TB that records errors instead of failing the
+// test.
+type errorRecorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestUseDefaultRegistry(t *testing.T) {
+	origRegisterer, origGatherer := prometheus.DefaultRegisterer, prometheus.DefaultGatherer
+
+	setup := func() {
+		prometheus.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "setup_total",
+			Help: "Registered on every setup.",
+		}))
+	}
+	for i := 0; i < 2; i++ {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			reg := UseDefaultRegistry(t, RegistryOpts{Pedantic: true})
+			if prometheus.DefaultRegisterer != reg || prometheus.DefaultGatherer != reg {
+				t.Fatal("test registry not installed as default")
+			}
+			setup() // Would panic on the second iteration without a fresh registry.
+			if got, err := GatherAndCount(reg, "setup_total"); err != nil || got != 1 {
+				t.Errorf("got %d metrics (err: %v), want 1", got, err)
+			}
+		})
+	}
+
+	if prometheus.DefaultRegisterer != origRegisterer || prometheus.DefaultGatherer != origGatherer {
+		t.Error("default registry not restored")
+	}
+}
+
+func TestUseDefaultRegistryLeaks(t *testing.T) {
+	// Install an empty registry as the previous default, so that escaped
+	// registrations do not pollute the real default registry.
+	UseDefaultRegistry(t, RegistryOpts{})
+	prev := prometheus.DefaultRegisterer.(*prometheus.Registry)
+
+	for _, tc := range []struct {
+		check        LeakCheck
+		expectErrors []string
+	}{
+		{check: IgnoreLeaks},
+		{check: ReportEscapedRegistrations, expectErrors: []string{"escaped"}},
+		{check: ReportAllLeaks, expectErrors: []string{"escaped", "remaining"}},
+	} {
+		rec := &errorRecorder{}
+		t.Run(fmt.Sprint(tc.check), func(t *testing.T) {
+			rec.TB = t
+			reg := UseDefaultRegistry(rec, RegistryOpts{LeakCheck: tc.check})
+
+			prev.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: fmt.Sprint("escaped_", tc.check), Help: "help"}))
+			reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "remaining", Help: "help"}))
+			unregistered := prometheus.NewGauge(prometheus.GaugeOpts{Name: "unregistered", Help: "help"})
+			reg.MustRegister(unregistered)
+			reg.Unregister(unregistered)
+		})
+
+		if got, want := len(rec.errors), len(tc.expectErrors); got != want {
+			t.Fatalf("leak check %d: got %d errors, want %d: %v", tc.check, got, want, rec.errors)
+		}
+		for i, want := range tc.expectErrors {
+			if !strings.Contains(rec.errors[i], want) {
+				t.Errorf("leak check %d: error %q does not mention %q", tc.check, rec.errors[i], want)
+			}
+			if strings.Contains(rec.errors[i], "unregistered") {
+				t.Errorf("leak check %d: error %q reports an unregistered collector", tc.check, rec.errors[i])
+			}
+		}
+	}
+}
+
+func TestNewRegistryParallel(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+
+			reg := NewRegistry(t, RegistryOpts{Pedantic: true, LeakCheck: ReportAllLeaks})
+			c := prometheus.NewCounter(prometheus.CounterOpts{Name: "parallel_total", Help: "help"})
+			reg.MustRegister(c)
+			c.Inc()
+			if got := ToFloat64(c); got != 1 {
+				t.Errorf("got %v, want 1", got)
+			}
+			reg.Unregister(c)
+		})
+	}
+}
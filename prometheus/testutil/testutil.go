@@ -35,6 +35,11 @@
 // In a similar pattern, CollectAndLint and GatherAndLint can be used to detect
 // metrics that have issues with their name, type, or metadata without being
 // necessarily invalid, e.g. a counter with a name missing the “_total” suffix.
+//
+// Code that registers its metrics with the default registry can be tested with
+// UseDefaultRegistry, which installs a fresh registry for the duration of a
+// test. Tests running in parallel should use NewRegistry and inject the
+// returned registry explicitly.
 package testutil
 
 import (
@@ -37,6 +37,6 @@ func NewUntypedFunc(opts UntypedOpts, function func() float64) UntypedFunc {
 		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
 		opts.Help,
 		nil,
-		opts.ConstLabels,
+		opts.ConstLabelSet.mergeInto(opts.ConstLabels),
 	), UntypedValue, function)
 }
@@ -43,9 +43,14 @@ type MetricVec struct {
 	hashAddByte func(h uint64, b byte) uint64
 }
 
-// NewMetricVec returns an initialized metricVec.
+// NewMetricVec returns an initialized metricVec. If the variable labels of desc
+// were declared with KnownValues (see ConstrainedLabel), the metrics for all
+// combinations of the known values are created right away. NewMetricVec panics
+// if the known values are declared for some but not all variable labels, if
+// they are not valid label values, or if there are more than
+// MaxPreInitializedMetrics combinations.
 func NewMetricVec(desc *Desc, newMetric func(lvs ...string) Metric) *MetricVec {
-	return &MetricVec{
+	m := &MetricVec{
 		metricMap: &metricMap{
 			metrics:   map[uint64][]metricWithLabelValues{},
 			desc:      desc,
@@ -54,6 +59,57 @@ func NewMetricVec(desc *Desc, newMetric func(lvs ...string) Metric) *MetricVec {
 		hashAdd:     hashAdd,
 		hashAddByte: hashAddByte,
 	}
+	m.initial = m.knownLabelValues()
+	m.metricMap.initialize()
+	return m
+}
+
+// knownLabelValues returns the hashes and label values of all combinations of
+// the known values of the variable labels.
+func (m *MetricVec) knownLabelValues() []hashedLabelValues {
+	if m.desc.err != nil || m.desc.variableLabels == nil || m.desc.variableLabels.knownValues == nil {
+		return nil
+	}
+	names := m.desc.variableLabels.names
+	known := m.desc.variableLabels.knownValues
+	n := 1
+	for i, values := range known {
+		if len(values) == 0 {
+			panic(fmt.Errorf("known values declared for some but not all variable labels of %q, missing for label %q", m.desc.fqName, names[i]))
+		}
+		n *= len(values)
+		if n > MaxPreInitializedMetrics {
+			panic(fmt.Errorf("known values of the variable labels of %q result in more than %d metrics", m.desc.fqName, MaxPreInitializedMetrics))
+		}
+	}
+
+	result := make([]hashedLabelValues, 0, n)
+	idx := make([]int, len(known))
+	for {
+		lvs := make([]string, len(known))
+		for i, values := range known {
+			lvs[i] = m.desc.variableLabels.constrain(names[i], values[idx[i]])
+		}
+		h, err := m.hashLabelValues(lvs)
+		if err != nil {
+			panic(err)
+		}
+		result = append(result, hashedLabelValues{hash: h, values: lvs})
+
+		// Advance to the next combination, with the last label changing
+		// fastest.
+		i := len(idx) - 1
+		for ; i >= 0; i-- {
+			idx[i]++
+			if idx[i] < len(known[i]) {
+				break
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			return result
+		}
+	}
 }
 
 // DeleteLabelValues removes the metric where the variable labels are the same
@@ -117,6 +173,64 @@ func (m *MetricVec) DeletePartialMatch(labels Labels) int {
 	return m.metricMap.deleteByLabels(labels, m.curry)
 }
 
+// DeleteMatching deletes all metrics whose variable labels match all of the
+// provided matchers. It returns the number of metrics deleted. Calling it
+// without any matchers deletes all metrics accessible through this vector.
+//
+// Matchers are evaluated against the label values as stored in the vector,
+// i.e. after any label constraints have been applied. A matcher for a label
+// name that is not a variable label matches no metric, so that a misspelled
+// label name in a negative matcher does not delete all metrics.
+//
+// In contrast to DeletePartialMatch, a curried vector only considers metrics
+// whose label values match the curried values, and matchers may refer to
+// curried labels.
+func (m *MetricVec) DeleteMatching(matchers ...*LabelMatcher) int {
+	return m.metricMap.deleteByMatchers(matchers, m.curry)
+}
+
+// ForEachLabelValues calls fn with the label values of each metric currently
+// accessible through this vector, in the same order as the variable labels in
+// Desc (minus any curried labels), i.e. in the order expected by
+// GetMetricWithLabelValues. Iteration stops early if fn returns false. The
+// order of iteration is not defined.
+//
+// ForEachLabelValues operates on a snapshot of the vector taken before the
+// first call of fn. It is therefore safe to create or delete metrics from
+// within fn. Metrics created during the iteration are not visited, while
+// metrics deleted during the iteration might still be visited. The slice
+// passed to fn is owned by fn.
+func (m *MetricVec) ForEachLabelValues(fn func(lvs []string) bool) {
+	for _, values := range m.metricMap.snapshotLabelValues(m.curry) {
+		if !fn(uncurryLabelValues(values, m.curry)) {
+			return
+		}
+	}
+}
+
+// ForEachLabels works like ForEachLabelValues but calls fn with the variable
+// labels (minus any curried labels) of each metric as a Labels map, i.e. in the
+// form expected by GetMetricWith. The map passed to fn is owned by fn.
+//
+// This method is used for the same purpose as ForEachLabelValues. It is more
+// convenient but comes with the overhead of creating a Labels map per metric.
+func (m *MetricVec) ForEachLabels(fn func(labels Labels) bool) {
+	for _, values := range m.metricMap.snapshotLabelValues(m.curry) {
+		labels := make(Labels, len(values)-len(m.curry))
+		iCurry := 0
+		for i, name := range m.desc.variableLabels.names {
+			if iCurry < len(m.curry) && m.curry[iCurry].index == i {
+				iCurry++
+				continue
+			}
+			labels[name] = values[i]
+		}
+		if !fn(labels) {
+			return
+		}
+	}
+}
+
 // Without explicit forwarding of Describe, Collect, Reset, those methods won't
 // show up in GoDoc.
 
@@ -126,7 +240,9 @@ func (m *MetricVec) Describe(ch chan<- *Desc) { m.metricMap.Describe(ch) }
 // Collect implements Collector.
 func (m *MetricVec) Collect(ch chan<- Metric) { m.metricMap.Collect(ch) }
 
-// Reset deletes all metrics in this vector.
+// Reset deletes all metrics in this vector. Metrics for the KnownValues of the
+// variable labels (see ConstrainedLabel) are re-created with their initial
+// value.
 func (m *MetricVec) Reset() { m.metricMap.Reset() }
 
 // CurryWith returns a vector curried with the provided labels, i.e. the
@@ -147,13 +263,28 @@ func (m *MetricVec) Reset() { m.metricMap.Reset() }
 // around MetricVec, implementing a vector for a specific Metric
 // implementation, for example GaugeVec.
 func (m *MetricVec) CurryWith(labels Labels) (*MetricVec, error) {
+	return m.curryWith(func(name string) (string, bool) {
+		val, ok := labels[name]
+		return val, ok
+	}, len(labels))
+}
+
+// CurryWithLabelSet works like CurryWith, but with the labels provided as
+// LabelSet.
+func (m *MetricVec) CurryWithLabelSet(ls LabelSet) (*MetricVec, error) {
+	return m.curryWith(ls.Get, ls.Len())
+}
+
+// curryWith implements CurryWith and CurryWithLabelSet. get looks up the value
+// of a label to curry, numLabels is the number of labels to curry.
+func (m *MetricVec) curryWith(get func(name string) (string, bool), numLabels int) (*MetricVec, error) {
 	var (
 		newCurry []curriedLabelValue
 		oldCurry = m.curry
 		iCurry   int
 	)
 	for i, labelName := range m.desc.variableLabels.names {
-		val, ok := labels[labelName]
+		val, ok := get(labelName)
 		if iCurry < len(oldCurry) && oldCurry[iCurry].index == i {
 			if ok {
 				return nil, fmt.Errorf("label name %q is already curried", labelName)
@@ -170,7 +301,7 @@ func (m *MetricVec) CurryWith(labels Labels) (*MetricVec, error) {
 			})
 		}
 	}
-	if l := len(oldCurry) + len(labels) - len(newCurry); l > 0 {
+	if l := len(oldCurry) + numLabels - len(newCurry); l > 0 {
 		return nil, fmt.Errorf("%d unknown label(s) found during currying", l)
 	}
 
@@ -247,6 +378,60 @@ func (m *MetricVec) GetMetricWith(labels Labels) (Metric, error) {
 	return m.metricMap.getOrCreateMetricWithLabels(h, labels, m.curry), nil
 }
 
+// GetMetricWithLabelSet works like GetMetricWith, but with the labels provided
+// as LabelSet. It does not allocate if the Metric exists already and the
+// variable labels have no constraints.
+func (m *MetricVec) GetMetricWithLabelSet(ls LabelSet) (Metric, error) {
+	var buf [8]string
+	lvs, err := m.labelSetValues(ls, buf[:0])
+	if err != nil {
+		return nil, err
+	}
+	h, err := m.hashLabelValues(lvs)
+	if err != nil {
+		return nil, err
+	}
+	return m.metricMap.getOrCreateMetricWithLabelValues(h, lvs, m.curry), nil
+}
+
+// DeleteLabelSet works like Delete, but with the labels provided as LabelSet.
+func (m *MetricVec) DeleteLabelSet(ls LabelSet) bool {
+	var buf [8]string
+	lvs, err := m.labelSetValues(ls, buf[:0])
+	if err != nil {
+		return false
+	}
+	h, err := m.hashLabelValues(lvs)
+	if err != nil {
+		return false
+	}
+	return m.metricMap.deleteByHashWithLabelValues(h, lvs, m.curry)
+}
+
+// labelSetValues appends the constrained values of the non-curried variable
+// labels in ls to lvs, in the order of the variable labels in Desc.
+func (m *MetricVec) labelSetValues(ls LabelSet, lvs []string) ([]string, error) {
+	if expected := len(m.desc.variableLabels.names) - len(m.curry); ls.Len() != expected {
+		return nil, fmt.Errorf(
+			"%w: expected %d label values but got %d in %s",
+			errInconsistentCardinality, expected, ls.Len(), ls,
+		)
+	}
+	var iCurry int
+	for i, name := range m.desc.variableLabels.names {
+		if iCurry < len(m.curry) && m.curry[iCurry].index == i {
+			iCurry++
+			continue
+		}
+		value, ok := ls.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("label name %q missing in label set %s", name, ls)
+		}
+		lvs = append(lvs, m.desc.variableLabels.constrain(name, value))
+	}
+	return lvs, nil
+}
+
 func (m *MetricVec) hashLabelValues(vals []string) (uint64, error) {
 	if err := validateLabelValues(vals, len(m.desc.variableLabels.names)-len(m.curry)); err != nil {
 		return 0, err
@@ -306,6 +491,12 @@ type metricWithLabelValues struct {
 	metric Metric
 }
 
+// hashedLabelValues is a set of label values with its hash.
+type hashedLabelValues struct {
+	hash   uint64
+	values []string
+}
+
 // curriedLabelValue sets the curried value for a label at the given index.
 type curriedLabelValue struct {
 	index int
@@ -319,6 +510,43 @@ type metricMap struct {
 	metrics   map[uint64][]metricWithLabelValues
 	desc      *Desc
 	newMetric func(labelValues ...string) Metric
+	// initial contains the label values of the metrics created upon
+	// construction and after each Reset.
+	initial []hashedLabelValues
+	// children and labelValueBytes track the number of metrics and the
+	// total length of their label values for memory accounting. Protected
+	// by mtx.
+	children, labelValueBytes int
+	// memory tracks the size of the metrics themselves.
+	memory memoryTracker
+}
+
+// track accounts for a metric that has been added (delta 1) or removed (delta
+// -1). Must be called while holding the write mutex.
+func (m *metricMap) track(metric metricWithLabelValues, delta int) {
+	m.children += delta
+	for _, lv := range metric.values {
+		m.labelValueBytes += delta * len(lv)
+	}
+	e, ok := metric.metric.(memoryEstimator)
+	if !ok {
+		return
+	}
+	// A trackable metric reports changes of its size only while connected
+	// to the tracker. Its size is estimated after connecting and after
+	// disconnecting, so that no change is lost or counted twice, save for
+	// changes happening concurrently with a deletion. The size of other
+	// metrics does not change after their creation.
+	if t, ok := e.(memoryTrackable); ok {
+		if delta > 0 {
+			t.setMemoryTracker(&m.memory)
+		} else {
+			t.setMemoryTracker(nil)
+		}
+	}
+	_, stats := e.estimateMemory()
+	// The label pairs are accounted for by the vector.
+	m.memory.add(delta*stats.buckets, delta*stats.exemplars, delta*(stats.bytes-stats.labelBytes))
 }
 
 // Describe implements Collector. It will send exactly one Desc to the provided
@@ -339,14 +567,45 @@ func (m *metricMap) Collect(ch chan<- Metric) {
 	}
 }
 
-// Reset deletes all metrics in this vector.
+// Reset deletes all metrics in this vector and then re-creates the metrics for
+// the known label values, if any.
 func (m *metricMap) Reset() {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
-	for h := range m.metrics {
+	for h, metrics := range m.metrics {
+		for _, metric := range metrics {
+			if t, ok := metric.metric.(memoryTrackable); ok {
+				t.setMemoryTracker(nil)
+			}
+		}
 		delete(m.metrics, h)
 	}
+	m.children, m.labelValueBytes = 0, 0
+	m.memory.reset()
+	m.initializeLocked()
+}
+
+// initialize creates the metrics for the known label values.
+func (m *metricMap) initialize() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.initializeLocked()
+}
+
+// initializeLocked is like initialize, but the caller must have locked m.mtx.
+// Known label values that collide with an existing metric are skipped.
+func (m *metricMap) initializeLocked() {
+	for _, hlvs := range m.initial {
+		if _, ok := m.getMetricWithHashAndLabelValues(hlvs.hash, hlvs.values, nil); ok {
+			continue
+		}
+		lvs := append([]string(nil), hlvs.values...)
+		metric := metricWithLabelValues{values: lvs, metric: m.newMetric(lvs...)}
+		m.metrics[hlvs.hash] = append(m.metrics[hlvs.hash], metric)
+		m.track(metric, 1)
+	}
 }
 
 // deleteByHashWithLabelValues removes the metric from the hash bucket h. If
@@ -368,6 +627,7 @@ func (m *metricMap) deleteByHashWithLabelValues(
 		return false
 	}
 
+	m.track(metrics[i], -1)
 	if len(metrics) > 1 {
 		old := metrics
 		m.metrics[h] = append(metrics[:i], metrics[i+1:]...)
@@ -396,6 +656,7 @@ func (m *metricMap) deleteByHashWithLabels(
 		return false
 	}
 
+	m.track(metrics[i], -1)
 	if len(metrics) > 1 {
 		old := metrics
 		m.metrics[h] = append(metrics[:i], metrics[i+1:]...)
@@ -419,6 +680,9 @@ func (m *metricMap) deleteByLabels(labels Labels, curry []curriedLabelValue) int
 			// Didn't find matching labels in this metric slice.
 			continue
 		}
+		for _, metric := range metrics {
+			m.track(metric, -1)
+		}
 		delete(m.metrics, h)
 		numDeleted++
 	}
@@ -426,6 +690,101 @@ func (m *metricMap) deleteByLabels(labels Labels, curry []curriedLabelValue) int
 	return numDeleted
 }
 
+// deleteByMatchers deletes all metrics matching the curried label values and
+// all of the given matchers. It returns the number of metrics deleted.
+func (m *metricMap) deleteByMatchers(matchers []*LabelMatcher, curry []curriedLabelValue) int {
+	// Resolve label names to indices once rather than for every metric.
+	indices := make([]int, len(matchers))
+	for i, matcher := range matchers {
+		j, ok := indexOf(matcher.Name, m.desc.variableLabels.names)
+		if !ok {
+			return 0
+		}
+		indices[i] = j
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var numDeleted int
+	for h, metrics := range m.metrics {
+		kept := metrics[:0]
+		for _, metric := range metrics {
+			if matchCurriedLabelValues(metric.values, curry) &&
+				matchLabelMatchers(metric.values, matchers, indices) {
+				m.track(metric, -1)
+				numDeleted++
+				continue
+			}
+			kept = append(kept, metric)
+		}
+		if len(kept) == 0 {
+			delete(m.metrics, h)
+			continue
+		}
+		// Clear the now unused tail to not hold on to deleted metrics.
+		for i := len(kept); i < len(metrics); i++ {
+			metrics[i] = metricWithLabelValues{}
+		}
+		m.metrics[h] = kept
+	}
+	return numDeleted
+}
+
+// snapshotLabelValues returns the (full) label values of all metrics matching
+// the curried label values.
+func (m *metricMap) snapshotLabelValues(curry []curriedLabelValue) [][]string {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	snapshot := make([][]string, 0, len(m.metrics))
+	for _, metrics := range m.metrics {
+		for _, metric := range metrics {
+			if matchCurriedLabelValues(metric.values, curry) {
+				snapshot = append(snapshot, metric.values)
+			}
+		}
+	}
+	return snapshot
+}
+
+// matchCurriedLabelValues returns whether the given label values contain all
+// the curried label values.
+func matchCurriedLabelValues(values []string, curry []curriedLabelValue) bool {
+	for _, c := range curry {
+		if values[c.index] != c.value {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLabelMatchers returns whether the given label values match all
+// matchers. indices contains the index of the label value for each matcher.
+func matchLabelMatchers(values []string, matchers []*LabelMatcher, indices []int) bool {
+	for i, matcher := range matchers {
+		if !matcher.Matches(values[indices[i]]) {
+			return false
+		}
+	}
+	return true
+}
+
+// uncurryLabelValues returns a copy of the given label values with the curried
+// label values taken out.
+func uncurryLabelValues(values []string, curry []curriedLabelValue) []string {
+	lvs := make([]string, 0, len(values)-len(curry))
+	iCurry := 0
+	for i, v := range values {
+		if iCurry < len(curry) && curry[iCurry].index == i {
+			iCurry++
+			continue
+		}
+		lvs = append(lvs, v)
+	}
+	return lvs
+}
+
 // findMetricWithPartialLabel returns the index of the matching metric or
 // len(metrics) if not found.
 func findMetricWithPartialLabels(
@@ -502,7 +861,9 @@ func (m *metricMap) getOrCreateMetricWithLabelValues(
 	if !ok {
 		inlinedLVs := inlineLabelValues(lvs, curry)
 		metric = m.newMetric(inlinedLVs...)
-		m.metrics[hash] = append(m.metrics[hash], metricWithLabelValues{values: inlinedLVs, metric: metric})
+		mwlv := metricWithLabelValues{values: inlinedLVs, metric: metric}
+		m.metrics[hash] = append(m.metrics[hash], mwlv)
+		m.track(mwlv, 1)
 	}
 	return metric
 }
@@ -527,7 +888,9 @@ func (m *metricMap) getOrCreateMetricWithLabels(
 	if !ok {
 		lvs := extractLabelValues(m.desc, labels, curry)
 		metric = m.newMetric(lvs...)
-		m.metrics[hash] = append(m.metrics[hash], metricWithLabelValues{values: lvs, metric: metric})
+		mwlv := metricWithLabelValues{values: lvs, metric: metric}
+		m.metrics[hash] = append(m.metrics[hash], mwlv)
+		m.track(mwlv, 1)
 	}
 	return metric
 }
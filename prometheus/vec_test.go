@@ -16,7 +16,9 @@ package prometheus
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"testing"
 
 	dto "github.com/prometheus/client_model/go"
@@ -260,6 +262,275 @@ func testDeletePartialMatch(t *testing.T, baseVec *GaugeVec) {
 	assertNoMetric(t)
 }
 
+func TestDeleteMatching(t *testing.T) {
+	vec := NewGaugeVec(
+		GaugeOpts{
+			Name: "test",
+			Help: "helpless",
+		},
+		[]string{"tenant", "code"},
+	)
+	// Force collisions to make sure only matching metrics in a bucket are
+	// deleted.
+	vec.hashAdd = func(h uint64, s string) uint64 { return 1 }
+	vec.hashAddByte = func(h uint64, b byte) uint64 { return 1 }
+
+	populate := func() {
+		vec.Reset()
+		vec.WithLabelValues("a", "200").Inc()
+		vec.WithLabelValues("a", "500").Inc()
+		vec.WithLabelValues("b", "200").Inc()
+		vec.WithLabelValues("c", "404").Inc()
+	}
+
+	for _, tc := range []struct {
+		name      string
+		matchers  []*LabelMatcher
+		deleted   int
+		remaining [][]string
+	}{
+		{
+			name:      "equal",
+			matchers:  []*LabelMatcher{MustNewLabelMatcher(MatchEqual, "tenant", "a")},
+			deleted:   2,
+			remaining: [][]string{{"b", "200"}, {"c", "404"}},
+		},
+		{
+			name:      "not in",
+			matchers:  []*LabelMatcher{MustNewLabelMatcher(MatchNotIn, "tenant", "a", "b")},
+			deleted:   1,
+			remaining: [][]string{{"a", "200"}, {"a", "500"}, {"b", "200"}},
+		},
+		{
+			name: "regexp and not equal",
+			matchers: []*LabelMatcher{
+				MustNewLabelMatcher(MatchRegexp, "code", "[24].."),
+				MustNewLabelMatcher(MatchNotEqual, "tenant", "c"),
+			},
+			deleted:   2,
+			remaining: [][]string{{"a", "500"}, {"c", "404"}},
+		},
+		{
+			name:      "anchored regexp",
+			matchers:  []*LabelMatcher{MustNewLabelMatcher(MatchNotRegexp, "code", "5")},
+			deleted:   4,
+			remaining: nil,
+		},
+		{
+			name:      "unknown label matches nothing",
+			matchers:  []*LabelMatcher{MustNewLabelMatcher(MatchEqual, "unknown", "")},
+			deleted:   0,
+			remaining: [][]string{{"a", "200"}, {"a", "500"}, {"b", "200"}, {"c", "404"}},
+		},
+		{
+			name: "misspelled label in negative matcher matches nothing",
+			matchers: []*LabelMatcher{
+				MustNewLabelMatcher(MatchNotEqual, "tenatn", "a"),
+				MustNewLabelMatcher(MatchRegexp, "code", ".*"),
+			},
+			deleted:   0,
+			remaining: [][]string{{"a", "200"}, {"a", "500"}, {"b", "200"}, {"c", "404"}},
+		},
+		{
+			name:      "in without match",
+			matchers:  []*LabelMatcher{MustNewLabelMatcher(MatchIn, "code", "503")},
+			deleted:   0,
+			remaining: [][]string{{"a", "200"}, {"a", "500"}, {"b", "200"}, {"c", "404"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			populate()
+			if got, want := vec.DeleteMatching(tc.matchers...), tc.deleted; got != want {
+				t.Errorf("got %v deleted, want %v", got, want)
+			}
+			if got, want := collectLabelValues(vec.MetricVec), tc.remaining; !reflect.DeepEqual(got, want) {
+				t.Errorf("got remaining %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDeleteMatchingCurried(t *testing.T) {
+	vec := NewGaugeVec(
+		GaugeOpts{
+			Name: "test",
+			Help: "helpless",
+		},
+		[]string{"tenant", "code"},
+	)
+	vec.WithLabelValues("a", "200").Inc()
+	vec.WithLabelValues("a", "500").Inc()
+	vec.WithLabelValues("b", "500").Inc()
+
+	curried := vec.MustCurryWith(Labels{"tenant": "a"})
+	if got, want := curried.DeleteMatching(MustNewLabelMatcher(MatchEqual, "code", "500")), 1; got != want {
+		t.Errorf("got %v deleted, want %v", got, want)
+	}
+	if got, want := curried.DeleteMatching(MustNewLabelMatcher(MatchEqual, "tenant", "b")), 0; got != want {
+		t.Errorf("got %v deleted, want %v", got, want)
+	}
+	if got, want := curried.DeleteMatching(), 1; got != want {
+		t.Errorf("got %v deleted, want %v", got, want)
+	}
+	if got, want := collectLabelValues(vec.MetricVec), [][]string{{"b", "500"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got remaining %v, want %v", got, want)
+	}
+}
+
+func TestNewLabelMatcherErrors(t *testing.T) {
+	for _, tc := range []struct {
+		typ    MatchType
+		values []string
+	}{
+		{typ: MatchEqual},
+		{typ: MatchNotEqual, values: []string{"a", "b"}},
+		{typ: MatchRegexp, values: []string{"("}},
+		{typ: MatchType(42), values: []string{"a"}},
+	} {
+		if _, err := NewLabelMatcher(tc.typ, "l", tc.values...); err == nil {
+			t.Errorf("expected error for matcher type %v with values %q", tc.typ, tc.values)
+		}
+	}
+}
+
+func TestForEachLabelValues(t *testing.T) {
+	vec := NewGaugeVec(
+		GaugeOpts{
+			Name: "test",
+			Help: "helpless",
+		},
+		[]string{"l1", "l2", "l3"},
+	)
+	vec.WithLabelValues("a", "x", "1").Inc()
+	vec.WithLabelValues("a", "y", "2").Inc()
+	vec.WithLabelValues("b", "x", "3").Inc()
+
+	curried := vec.MustCurryWith(Labels{"l2": "x"})
+
+	var got [][]string
+	curried.ForEachLabelValues(func(lvs []string) bool {
+		got = append(got, lvs)
+		return true
+	})
+	sortLabelValues(got)
+	if want := [][]string{{"a", "1"}, {"b", "3"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	var gotLabels []Labels
+	curried.ForEachLabels(func(labels Labels) bool {
+		gotLabels = append(gotLabels, labels)
+		return true
+	})
+	if len(gotLabels) != 2 {
+		t.Fatalf("got %d label sets, want 2", len(gotLabels))
+	}
+	for _, labels := range gotLabels {
+		if _, ok := labels["l2"]; ok || len(labels) != 2 {
+			t.Errorf("unexpected labels %v for curried vector", labels)
+		}
+	}
+
+	// Deleting from within the callback must not deadlock, and returning
+	// false must stop the iteration.
+	var calls int
+	vec.ForEachLabelValues(func(lvs []string) bool {
+		calls++
+		vec.DeleteLabelValues(lvs...)
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+	if got := len(collectLabelValues(vec.MetricVec)); got != 2 {
+		t.Errorf("got %d remaining metrics, want 2", got)
+	}
+}
+
+func collectLabelValues(vec *MetricVec) [][]string {
+	var lvs [][]string
+	vec.ForEachLabelValues(func(values []string) bool {
+		lvs = append(lvs, values)
+		return true
+	})
+	sortLabelValues(lvs)
+	return lvs
+}
+
+func sortLabelValues(lvs [][]string) {
+	sort.Slice(lvs, func(i, j int) bool {
+		return strings.Join(lvs[i], "\xff") < strings.Join(lvs[j], "\xff")
+	})
+}
+
+func TestKnownValues(t *testing.T) {
+	vec := V2.NewCounterVec(CounterVecOpts{
+		CounterOpts: CounterOpts{
+			Name: "test",
+			Help: "helpless",
+		},
+		VariableLabels: ConstrainedLabels{
+			{Name: "code", KnownValues: []string{"200", "500"}},
+			{Name: "method", KnownValues: []string{"GET", "post"}, Constraint: strings.ToUpper},
+		},
+	})
+	want := [][]string{{"200", "GET"}, {"200", "POST"}, {"500", "GET"}, {"500", "POST"}}
+	if got := collectLabelValues(vec.MetricVec); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Pre-initialized metrics are found by their (constrained) label values.
+	vec.WithLabelValues("500", "post").Inc()
+	vec.WithLabelValues("404", "GET").Inc()
+	if got := len(collectLabelValues(vec.MetricVec)); got != 5 {
+		t.Errorf("got %d metrics, want 5", got)
+	}
+
+	if !vec.DeleteLabelValues("200", "GET") {
+		t.Error("pre-initialized metric not deleted")
+	}
+	if got := len(collectLabelValues(vec.MetricVec)); got != 4 {
+		t.Errorf("got %d metrics after delete, want 4", got)
+	}
+
+	// Reset re-creates all pre-initialized metrics at zero.
+	vec.Reset()
+	if got := collectLabelValues(vec.MetricVec); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v after reset, want %v", got, want)
+	}
+	m := &dto.Metric{}
+	if err := vec.WithLabelValues("500", "POST").Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetCounter().GetValue(); got != 0 {
+		t.Errorf("got value %v after reset, want 0", got)
+	}
+}
+
+func TestKnownValuesInvalid(t *testing.T) {
+	tooMany := make([]string, 101)
+	for i := range tooMany {
+		tooMany[i] = strconv.Itoa(i)
+	}
+	for name, labels := range map[string]ConstrainedLabels{
+		"partial":  {{Name: "a", KnownValues: []string{"x"}}, {Name: "b"}},
+		"too many": {{Name: "a", KnownValues: tooMany}, {Name: "b", KnownValues: tooMany}},
+		"invalid":  {{Name: "a", KnownValues: []string{"\xff"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			V2.NewGaugeVec(GaugeVecOpts{
+				GaugeOpts:      GaugeOpts{Name: "test", Help: "helpless"},
+				VariableLabels: labels,
+			})
+		})
+	}
+}
+
 func TestMetricVec(t *testing.T) {
 	vec := NewGaugeVec(
 		GaugeOpts{
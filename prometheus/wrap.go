@@ -16,6 +16,9 @@ package prometheus
 import (
 	"fmt"
 	"sort"
+	"unicode/utf8"
+
+	"github.com/prometheus/common/model"
 
 	"github.com/prometheus/client_golang/prometheus/internal"
 
@@ -50,6 +53,12 @@ func WrapRegistererWith(labels Labels, reg Registerer) Registerer {
 	}
 }
 
+// WrapRegistererWithLabelSet works like WrapRegistererWith, but with the labels
+// provided as LabelSet.
+func WrapRegistererWithLabelSet(ls LabelSet, reg Registerer) Registerer {
+	return WrapRegistererWith(ls.Labels(), reg)
+}
+
 // WrapRegistererWithPrefix returns a Registerer wrapping the provided
 // Registerer. Collectors registered with the returned Registerer will be
 // registered with the wrapped Registerer in a modified way. The modified
@@ -212,3 +221,169 @@ func wrapDesc(desc *Desc, prefix string, labels Labels) *Desc {
 	}
 	return newDesc
 }
+
+// WrapGathererWith returns a Gatherer wrapping the provided Gatherer. The
+// returned Gatherer adds the provided Labels to all Metrics gathered from the
+// wrapped Gatherer. It is the counterpart of WrapRegistererWith for Gatherers
+// that are not under the control of the caller, e.g. those of third-party
+// libraries that are merged with Gatherers.
+//
+// Metrics that already have one of the labels are treated like by
+// WrapRegistererWith, i.e. they are considered invalid: They are dropped, and
+// an error is reported (while all other Metrics are still returned, as is
+// usual for Gatherers). The result is sorted and checked for consistency like
+// the result of Gatherers.Gather. Invalid wrapping labels (including label
+// names with the reserved "__" prefix) invalidate all Metrics, so that no
+// Metrics are returned at all, only an error.
+//
+// The MetricFamily protobufs gathered from the wrapped Gatherer are not
+// modified. Wrapping a nil value is valid, resulting in a Gatherer that never
+// returns any metrics.
+func WrapGathererWith(labels Labels, g Gatherer) Gatherer {
+	return GathererFunc(func() ([]*dto.MetricFamily, error) {
+		if g == nil {
+			return nil, nil
+		}
+		mfs, err := g.Gather()
+		return wrapMetricFamilies(mfs, err, "", labels)
+	})
+}
+
+// WrapGathererWithPrefix returns a Gatherer wrapping the provided Gatherer.
+// The returned Gatherer adds the provided prefix to the name of all
+// MetricFamilies gathered from the wrapped Gatherer. It is the counterpart of
+// WrapRegistererWithPrefix, and the same caveats apply. See WrapGathererWith
+// for the handling of errors. Wrapping a nil value is valid, resulting in a
+// Gatherer that never returns any metrics.
+func WrapGathererWithPrefix(prefix string, g Gatherer) Gatherer {
+	return GathererFunc(func() ([]*dto.MetricFamily, error) {
+		if g == nil {
+			return nil, nil
+		}
+		mfs, err := g.Gather()
+		return wrapMetricFamilies(mfs, err, prefix, nil)
+	})
+}
+
+// WrapTransactionalGathererWith works like WrapGathererWith, but for a
+// TransactionalGatherer. The done function of the wrapped TransactionalGatherer
+// is returned unchanged. As the gathered MetricFamily protobufs are not
+// modified, this is safe for TransactionalGatherers returning cached metrics.
+func WrapTransactionalGathererWith(labels Labels, g TransactionalGatherer) TransactionalGatherer {
+	return &wrappingTransactionalGatherer{wrappedGatherer: g, labels: labels}
+}
+
+// WrapTransactionalGathererWithPrefix works like WrapGathererWithPrefix, but
+// for a TransactionalGatherer. See WrapTransactionalGathererWith for details.
+func WrapTransactionalGathererWithPrefix(prefix string, g TransactionalGatherer) TransactionalGatherer {
+	return &wrappingTransactionalGatherer{wrappedGatherer: g, prefix: prefix}
+}
+
+type wrappingTransactionalGatherer struct {
+	wrappedGatherer TransactionalGatherer
+	prefix          string
+	labels          Labels
+}
+
+// Gather implements TransactionalGatherer.
+func (g *wrappingTransactionalGatherer) Gather() ([]*dto.MetricFamily, func(), error) {
+	if g.wrappedGatherer == nil {
+		return nil, func() {}, nil
+	}
+	mfs, done, err := g.wrappedGatherer.Gather()
+	mfs, err = wrapMetricFamilies(mfs, err, g.prefix, g.labels)
+	return mfs, done, err
+}
+
+// wrapMetricFamilies returns copies of the provided MetricFamilies with the
+// prefix added to their names and the labels added to their Metrics. The
+// Metrics themselves are shallow copies sharing everything but their label
+// pairs with the originals. err is the error returned by the wrapped Gatherer,
+// which is included in the returned error.
+func wrapMetricFamilies(mfs []*dto.MetricFamily, err error, prefix string, labels Labels) ([]*dto.MetricFamily, error) {
+	var errs MultiError
+	errs.Append(err)
+
+	wrappingPairs := make([]*dto.LabelPair, 0, len(labels))
+	for ln, lv := range labels {
+		wrappingPairs = append(wrappingPairs, &dto.LabelPair{
+			Name:  proto.String(ln),
+			Value: proto.String(lv),
+		})
+	}
+	sort.Sort(internal.LabelPairSorter(wrappingPairs))
+	var invalidLabels bool
+	for _, lp := range wrappingPairs {
+		if !checkLabelName(lp.GetName()) {
+			errs = append(errs, fmt.Errorf("wrapping label name %q is invalid", lp.GetName()))
+			invalidLabels = true
+		}
+		if !utf8.ValidString(lp.GetValue()) {
+			errs = append(errs, fmt.Errorf("wrapping label value %q for label %q is not valid UTF-8", lp.GetValue(), lp.GetName()))
+			invalidLabels = true
+		}
+	}
+	if invalidLabels {
+		// None of the Metrics could be wrapped into a valid exposition.
+		return nil, errs
+	}
+
+	var (
+		metricFamiliesByName = make(map[string]*dto.MetricFamily, len(mfs))
+		metricHashes         = map[uint64]struct{}{}
+	)
+	for _, mf := range mfs {
+		name := prefix + mf.GetName()
+		if !model.IsValidMetricName(model.LabelValue(name)) {
+			errs = append(errs, fmt.Errorf("%q is not a valid metric name", name))
+			continue
+		}
+		wrappedMF, exists := metricFamiliesByName[name]
+		if !exists {
+			wrappedMF = &dto.MetricFamily{
+				Name: proto.String(name),
+				Help: mf.Help,
+				Type: mf.Type,
+				Unit: mf.Unit,
+			}
+			if err := checkSuffixCollisions(wrappedMF, metricFamiliesByName); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			metricFamiliesByName[name] = wrappedMF
+		}
+	metrics:
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if _, alreadyUsed := labels[lp.GetName()]; alreadyUsed {
+					errs = append(errs, fmt.Errorf(
+						"gathered metric %q { %s}: attempted wrapping with already existing label name %q",
+						name, m, lp.GetName(),
+					))
+					continue metrics
+				}
+			}
+			wrappedM := &dto.Metric{
+				Label:       m.Label,
+				Gauge:       m.Gauge,
+				Counter:     m.Counter,
+				Summary:     m.Summary,
+				Untyped:     m.Untyped,
+				Histogram:   m.Histogram,
+				TimestampMs: m.TimestampMs,
+			}
+			if len(wrappingPairs) > 0 {
+				wrappedM.Label = make([]*dto.LabelPair, 0, len(m.Label)+len(wrappingPairs))
+				wrappedM.Label = append(wrappedM.Label, m.Label...)
+				wrappedM.Label = append(wrappedM.Label, wrappingPairs...)
+				sort.Sort(internal.LabelPairSorter(wrappedM.Label))
+			}
+			if err := checkMetricConsistency(wrappedMF, wrappedM, metricHashes); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			wrappedMF.Metric = append(wrappedMF.Metric, wrappedM)
+		}
+	}
+	return internal.NormalizeMetricFamilies(metricFamiliesByName), errs.MaybeUnwrap()
+}
@@ -339,3 +339,125 @@ func TestNil(t *testing.T) {
 		t.Fatal("registering failed:", err)
 	}
 }
+
+func TestWrapGatherer(t *testing.T) {
+	reg := NewRegistry()
+	counter := NewCounterVec(CounterOpts{Name: "requests_total", Help: "help"}, []string{"code"})
+	counter.WithLabelValues("200").Add(2)
+	counter.WithLabelValues("500").Inc()
+	conflicting := NewGauge(GaugeOpts{Name: "conflicting", Help: "help", ConstLabels: Labels{"source": "x"}})
+	reg.MustRegister(counter, conflicting)
+	original, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalString := fmt.Sprint(original)
+
+	labeled := WrapGathererWith(Labels{"source": "lib", "a": "b"}, reg)
+	mfs, err := labeled.Gather()
+	if err == nil || !strings.Contains(err.Error(), `attempted wrapping with already existing label name "source"`) {
+		t.Errorf("expected collision error, got %v", err)
+	}
+	if len(mfs) != 1 || mfs[0].GetName() != "requests_total" || len(mfs[0].Metric) != 2 {
+		t.Fatalf("unexpected metric families: %v", mfs)
+	}
+	for i, code := range []string{"200", "500"} {
+		var got []string
+		for _, lp := range mfs[0].Metric[i].Label {
+			got = append(got, lp.GetName()+"="+lp.GetValue())
+		}
+		if want := []string{"a=b", "code=" + code, "source=lib"}; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("metric %d: want labels %v, got %v", i, want, got)
+		}
+	}
+
+	// Prefixing re-sorts the families and can be combined with labels.
+	reg2 := NewRegistry()
+	reg2.MustRegister(NewGauge(GaugeOpts{Name: "zzz", Help: "help"}))
+	prefixed := Gatherers{
+		WrapGathererWithPrefix("aaa_", reg2),
+		WrapGathererWith(Labels{"source": "lib"}, WrapGathererWithPrefix("lib_", GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return reg.Gather()
+		}))),
+	}
+	mfs, err = prefixed.Gather()
+	if err == nil {
+		t.Error("expected collision error")
+	}
+	var names []string
+	for _, mf := range mfs {
+		names = append(names, mf.GetName())
+	}
+	if want := []string{"aaa_zzz", "lib_requests_total"}; fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("want families %v, got %v", want, names)
+	}
+
+	// Invalid names are detected.
+	_, err = WrapGathererWithPrefix("\xff", reg2).Gather()
+	if err == nil || !strings.Contains(err.Error(), "not a valid metric name") {
+		t.Errorf("expected invalid name error, got %v", err)
+	}
+
+	// Invalid wrapping labels are rejected up front.
+	for _, labels := range []Labels{
+		{"": "x"},
+		{"\xff": "x"},
+		{"__name__": "x"},
+		{"good": "\xff"},
+	} {
+		mfs, err := WrapGathererWith(labels, reg2).Gather()
+		if err == nil || mfs != nil {
+			t.Errorf("wrapping with %v: expected error and no metrics, got %v, %v", labels, mfs, err)
+		}
+	}
+
+	// Wrapping without prefix or labels still sorts and checks the result.
+	mfs, err = WrapGathererWith(nil, GathererFunc(func() ([]*dto.MetricFamily, error) {
+		got, err := reg2.Gather()
+		return append(append(got, mfs...), got...), err
+	})).Gather()
+	if err == nil || !strings.Contains(err.Error(), "collected before with the same name and label values") {
+		t.Errorf("expected duplicate error, got %v", err)
+	}
+	names = names[:0]
+	for _, mf := range mfs {
+		names = append(names, mf.GetName())
+	}
+	if want := []string{"aaa_zzz", "lib_requests_total", "zzz"}; fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("want families %v, got %v", want, names)
+	}
+
+	// The transactional variants pass done through.
+	var doneCalled bool
+	tg := WrapTransactionalGathererWithPrefix("p_", transactionalGathererFunc(func() ([]*dto.MetricFamily, func(), error) {
+		mfs, err := reg2.Gather()
+		return mfs, func() { doneCalled = true }, err
+	}))
+	mfs, done, err := WrapTransactionalGathererWith(Labels{"l": "v"}, tg).Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done()
+	if !doneCalled {
+		t.Error("done of the wrapped TransactionalGatherer not called")
+	}
+	if len(mfs) != 1 || mfs[0].GetName() != "p_zzz" || mfs[0].Metric[0].Label[0].GetName() != "l" {
+		t.Errorf("unexpected metric families: %v", mfs)
+	}
+
+	// The gathered families are not modified.
+	if got, err := reg.Gather(); err != nil || fmt.Sprint(got) != originalString {
+		t.Errorf("wrapped families modified: %v (error %v)", got, err)
+	}
+
+	// Wrapping nil is valid.
+	if mfs, err := WrapGathererWith(Labels{"a": "b"}, nil).Gather(); mfs != nil || err != nil {
+		t.Errorf("unexpected result of wrapped nil Gatherer: %v, %v", mfs, err)
+	}
+}
+
+type transactionalGathererFunc func() ([]*dto.MetricFamily, func(), error)
+
+func (f transactionalGathererFunc) Gather() ([]*dto.MetricFamily, func(), error) {
+	return f()
+}